@@ -0,0 +1,92 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// projectNameTemplate is replaced with name of project in flag values when several projects are processed
+const projectNameTemplate = "{}"
+
+// errBatchDone is returned by load when command was already run for every project of batch
+var errBatchDone = errors.New("batch is done")
+
+// commandRuns contains run functions of commands by name (filled in init to avoid initialization cycle)
+var commandRuns = map[string]func(args []string) error{}
+
+func init() {
+	for _, c := range commands {
+		commandRuns[c.name] = c.run
+	}
+}
+
+// batchProjects returns project paths given as positional args expanding glob patterns (e.g. apps/*/);
+// false is returned if there is only one project given without pattern
+func batchProjects(args []string) ([]string, bool, error) {
+	if len(args) == 1 && !hasGlobMeta(args[0]) {
+		return nil, false, nil
+	}
+	var projects []string
+	for _, a := range args {
+		if !hasGlobMeta(a) {
+			projects = append(projects, a)
+			continue
+		}
+		matches, err := filepath.Glob(a)
+		if err != nil {
+			return nil, true, fmt.Errorf("invalid project pattern '%s': %v", a, err)
+		}
+		if len(matches) == 0 {
+			return nil, true, fmt.Errorf("no projects match '%s'", a)
+		}
+		projects = append(projects, matches...)
+	}
+	return projects, true, nil
+}
+
+func hasGlobMeta(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
+
+// runBatch runs command for every project with {} in flags replaced by project name (base name of its dir);
+// failures are printed and do not stop processing of the rest of projects
+func runBatch(run func(args []string) error, flags []string, projects []string) error {
+	failed := 0
+	for _, p := range projects {
+		name := filepath.Base(filepath.Clean(p))
+		args := make([]string, 0, len(flags)+1)
+		for _, f := range flags {
+			args = append(args, strings.Replace(f, projectNameTemplate, name, -1))
+		}
+		fmt.Fprintf(os.Stderr, "%s:\n", p)
+		if err := run(append(args, p)); err != nil {
+			if err != errUsage {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", p, err)
+			}
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d projects failed", failed, len(projects))
+	}
+	return nil
+}
+
+// batch runs command of flag set for every project if several of them are given (see batchProjects);
+// errBatchDone is returned if all of them succeeded
+func (fs *cmdFlags) batch(args []string) error {
+	projects, ok, err := batchProjects(fs.Args())
+	if !ok {
+		return nil
+	}
+	if err == nil {
+		err = runBatch(commandRuns[fs.Name()], args[:len(args)-fs.NArg()], projects)
+	}
+	if err == nil {
+		err = errBatchDone
+	}
+	return err
+}
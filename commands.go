@@ -0,0 +1,619 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/vc2402/localizer/engine"
+)
+
+// stdio is file name meaning stdin or stdout
+const stdio = "-"
+
+func exportCmd(args []string) error {
+	fs := newFlagSet("export")
+	fs.csvFlags()
+	fs.placeholderFlags()
+	fs.filterFlags()
+	fileF := fs.String("file", "", "`path` to file to export values to (- for stdout)")
+	formatF := fs.String("format", "", "file `format` (default: guessed by file extension, csv for stdout)")
+	templF := fs.Bool("template", false, "export csv with default values and empty locale columns")
+	changedF := fs.Bool("changed", false, "export to csv only strings whose default value changed since previous -changed export")
+	stateF := fs.String("state", "", "`path` to state file for -changed (default: .localizer-state.json in resources dir)")
+	staleF := fs.Bool("stale", false, "export to csv only strings that are missing or whose default value changed since translation in any locale")
+	sinceF := fs.String("since", "", "`path` to previous csv export: export only strings that are new or whose default value changed since it (with delta column)")
+	clearedF := fs.Bool("cleared", false, "with -since: export also strings whose translations were cleared since previous export")
+	dirF := fs.String("dir", "", "`path` to dir to export files of format with file per locale (apple, arb, csv, properties, xliff) to")
+	nonTrF := fs.Bool("include-nontranslatable", false, "include non-translatable strings (apple format; csv with translatable column)")
+	onlyMissingF := fs.Bool("only-missing", false, "export only strings missing in the file's locale (csv to dir)")
+	refsF := fs.Bool("references", false, "export strings referring to other strings (@string/...) with resolved value in context column (csv)")
+	argsF := fs.Bool("args", false, "add args column describing placeholders of default values (csv)")
+	watchF := fs.Bool("watch", false, "export to file again every time resources are changed (until interrupted)")
+	sortF := fs.String("sort", "alpha", "`order` of csv rows: alpha, order (as in resources) or group (related strings together)")
+	groupsF := fs.String("groups", "", "coma-separated name `prefixes` of groups for -sort group in required order (e.g. onboarding_,settings_); others are grouped by part of name before _")
+	eng, err := fs.load(args)
+	if err != nil {
+		return err
+	}
+	order, err := engine.ParseRowOrder(*sortF)
+	if err != nil {
+		return err
+	}
+	setup := func(eng *engine.Localizer) {
+		eng.SetIncludeNonTranslatable(*nonTrF).SetExportReferences(*refsF).SetOnlyMissing(*onlyMissingF).SetExportArguments(*argsF).
+			SetRowOrder(order).SetRowGroups(splitList(*groupsF)...)
+	}
+	setup(eng)
+	printWarnings(eng.CheckReferences())
+	if *watchF {
+		if *fileF == "" || *fileF == stdio || *dirF != "" || *templF || *changedF || *staleF || *sinceF != "" {
+			return fmt.Errorf("-watch is supported for plain export to file only")
+		}
+		return watchExport(fs, eng, *fileF, *formatF, setup)
+	}
+	if *dirF != "" {
+		if err = fs.requireFlag("format", *formatF); err != nil {
+			return err
+		}
+		return eng.ExportToDir(*dirF, *formatF)
+	}
+	if err = fs.requireFlag("file", *fileF); err != nil {
+		return err
+	}
+	if *templF {
+		if *fileF == stdio {
+			return eng.ExportTemplateW(os.Stdout)
+		}
+		return eng.ExportTemplate(*fileF)
+	}
+	if *changedF {
+		return exportChanged(eng, *fileF, *stateF)
+	}
+	if *staleF {
+		if *fileF == stdio {
+			return eng.ExportStaleW(os.Stdout)
+		}
+		return eng.ExportStale(*fileF)
+	}
+	if *sinceF != "" {
+		return exportDelta(eng.SetDeltaCleared(*clearedF), *fileF, *sinceF)
+	}
+	return exportTo(eng, *fileF, *formatF)
+}
+
+func importCmd(args []string) error {
+	fs := newFlagSet("import")
+	fs.csvFlags()
+	fs.placeholderFlags()
+	fs.saveFlags()
+	fileF := fs.String("file", "", "`path` to file to import values from (- for stdin)")
+	formatF := fs.String("format", "", "file `format` (default: guessed by file extension, csv for stdin)")
+	maxExpF := fs.Float64("max-expansion", 0, "warn about translations longer than `ratio` * default value length")
+	watchF := fs.Bool("watch", false, "import file again every time it is changed (until interrupted)")
+	dirF := fs.String("dir", "", "`path` to dir to import files of format with file per locale (arb, csv, properties, xliff) from")
+	importLocF := fs.String("import-locales", "", "coma-separated `locales` to take values for (all the locales of file by default)")
+	fs.forceRefs = fs.Bool("force-references", false, "replace locale values that are references to other strings (e.g. @string/app_name)")
+	fs.normNewlines = fs.Bool("normalize-newlines", false, "collapse double-escaped line breaks (\\\\n) of imported values into \\n")
+	eng, err := fs.load(args)
+	if err != nil {
+		return err
+	}
+	if fs.importLocales, err = parseLocales(*importLocF); err != nil {
+		return err
+	}
+	eng.SetImportLocales(fs.importLocales...)
+	if *dirF != "" {
+		return importDir(eng, *dirF, defaultFormat(*formatF))
+	}
+	if err = fs.requireFlag("file", *fileF); err != nil {
+		return err
+	}
+	if *watchF {
+		if *fileF == stdio {
+			return fmt.Errorf("stdin can not be watched")
+		}
+		return watchImport(fs, *fileF, *formatF, *maxExpF)
+	}
+	if err = importFrom(eng, *fileF, *formatF); err != nil {
+		return err
+	}
+	if sum := eng.LastImport(); sum != nil && *fs.verbose {
+		fmt.Fprintln(os.Stderr, sum)
+	}
+	printWarnings(eng.SetMaxExpansion(*maxExpF).ValidateLengths(nil))
+	return save(eng)
+}
+
+func reportCmd(args []string) error {
+	fs := newFlagSet("report")
+	fs.filterFlags()
+	fs.reviewFlags()
+	jsonF := fs.Bool("json", false, "print report as json (statistics, missing and stale strings by locale, validation issues)")
+	eng, err := fs.load(args)
+	if err != nil {
+		return err
+	}
+	if *jsonF {
+		return eng.ReportJSON(os.Stdout)
+	}
+	return eng.Report().WriteTable(os.Stdout)
+}
+
+func checkCmd(args []string) error {
+	fs := newFlagSet("check")
+	fs.filterFlags()
+	fs.reviewFlags()
+	minF := fs.Float64("min-complete", 100, "min `percent` of translated strings required for every locale")
+	requireF := fs.String("require-locales", "", "coma-separated `locales` that must have all the strings translated")
+	coverageF := fs.String("min-coverage", "", "min `percents` of translated strings for every locale (95), by locale (de=100,fr=95) or both (90,fr=95); overrides -min-complete")
+	eng, err := fs.load(args)
+	if err != nil {
+		return err
+	}
+	required, err := parseLocales(*requireF)
+	if err != nil {
+		return err
+	}
+	min, thresholds, err := parseCoverage(*coverageF, *minF)
+	if err != nil {
+		return err
+	}
+	failures := 0
+	failed := map[string]bool{}
+	for _, e := range eng.CheckCoverage(min, thresholds) {
+		fmt.Println(e)
+		if ce, ok := e.(*engine.CoverageError); ok {
+			failed[ce.Locale] = true
+		}
+		failures++
+	}
+	stats := map[string]engine.LocaleStats{}
+	for _, st := range eng.Stats() {
+		stats[st.Locale] = st
+	}
+	for _, loc := range required {
+		st, ok := stats[loc]
+		if !ok {
+			fmt.Printf("%s: required locale not found\n", loc)
+			failures++
+		} else if st.Missing() > 0 && !failed[loc] {
+			fmt.Printf("%s: %d missing, required to be complete\n", loc, st.Missing())
+			failures++
+		}
+	}
+	for _, st := range eng.Stats() {
+		if failed[st.Locale] || (st.Missing() > 0 && contains(required, st.Locale)) {
+			for _, n := range eng.Missing(st.Locale) {
+				fmt.Printf("%s: missing %s\n", st.Locale, n)
+			}
+		}
+	}
+	for _, e := range eng.ValidatePlaceholders() {
+		fmt.Println(e)
+		failures++
+	}
+	for _, e := range eng.NonTranslatableValues() {
+		fmt.Println(e)
+		failures++
+	}
+	stale := eng.StaleTranslations()
+	for _, loc := range eng.Locales[1:] {
+		for _, n := range stale[loc] {
+			fmt.Fprintf(os.Stderr, "warning: %s: stale %s (default value changed since translation)\n", loc, n)
+		}
+	}
+	printWarnings(eng.CheckReferences())
+	if failures > 0 {
+		return fmt.Errorf("check failed: %d problems", failures)
+	}
+	return nil
+}
+
+func validateCmd(args []string) error {
+	fs := newFlagSet("validate")
+	fs.filterFlags()
+	maxExpF := fs.Float64("max-expansion", 0, "report translations longer than `ratio` * default value length")
+	icuF := fs.Bool("icu", false, "check ICU MessageFormat strings (with plural or select arguments or matching -icu-names)")
+	icuNamesF := fs.String("icu-names", "", "coma-separated name `patterns` (e.g. icu_*) of ICU MessageFormat strings")
+	eng, err := fs.load(args)
+	if err != nil {
+		return err
+	}
+	errs := eng.SetMaxExpansion(*maxExpF).ValidateLengths(nil)
+	errs = append(errs, eng.ValidateNewlines()...)
+	if *icuF || *icuNamesF != "" {
+		errs = append(errs, eng.SetICUPatterns(splitList(*icuNamesF)...).ValidateICU()...)
+	}
+	if err = eng.CheckRoundTrip(); err != nil {
+		errs = append(errs, fmt.Errorf("round-trip: %v", err))
+	}
+	for _, e := range errs {
+		fmt.Println(e)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("validation failed: %d problems", len(errs))
+	}
+	return nil
+}
+
+func unusedCmd(args []string) error {
+	fs := newFlagSet("unused")
+	fs.saveFlags()
+	pruneF := fs.Bool("prune", false, "remove unused strings from locale files")
+	srcF := fs.String("src", "", "coma-separated `dirs` to scan for string references (default: parent of resources dir)")
+	keepF := fs.String("keep", "", "coma-separated name `patterns` (e.g. debug_*) never reported as unused")
+	keepPrefixF := fs.String("keep-prefix", "", "coma-separated name `prefixes` (e.g. debug_,remote_) never reported as unused")
+	eng, err := fs.load(args)
+	if err != nil {
+		return err
+	}
+	unused, err := eng.SetUnusedWhitelist(splitList(*keepF)...).SetUnusedPrefixes(splitList(*keepPrefixF)...).FindUnused(splitList(*srcF)...)
+	if err != nil {
+		return err
+	}
+	for _, n := range unused {
+		fmt.Println(n)
+	}
+	if *pruneF {
+		return save(eng.Remove(unused...))
+	}
+	return nil
+}
+
+// exportTo exports values to file or to stdout if fileName is stdio
+func exportTo(eng *engine.Localizer, fileName, format string) error {
+	if fileName != stdio {
+		return eng.ExportFile(fileName, format)
+	}
+	f, err := engine.FormatByName(defaultFormat(format))
+	if err != nil {
+		return err
+	}
+	return f.Export(eng, os.Stdout)
+}
+
+// exportChanged exports strings changed since previous export and updates state file
+func exportChanged(eng *engine.Localizer, fileName, stateFile string) error {
+	if stateFile == "" {
+		stateFile = eng.StateFileName()
+	}
+	st, err := eng.ReadState(stateFile)
+	if err != nil {
+		return err
+	}
+	if fileName == stdio {
+		err = eng.ExportChangedW(os.Stdout, st)
+	} else {
+		err = eng.ExportChanged(fileName, st)
+	}
+	if err != nil {
+		return err
+	}
+	return eng.WriteState(st, stateFile)
+}
+
+// exportDelta exports to csv file (or stdout) strings changed since previous export in file since
+func exportDelta(eng *engine.Localizer, fileName, since string) error {
+	if fileName == stdio {
+		return eng.ExportDeltaOf(os.Stdout, since)
+	}
+	return eng.ExportDeltaFile(fileName, since)
+}
+
+// importDir imports files from dir printing results of every file (for csv)
+// and saves values of imported ones even if some files failed
+func importDir(eng *engine.Localizer, dir, format string) error {
+	err := eng.ImportFromDir(dir, format)
+	for _, res := range eng.LastDirImport() {
+		fmt.Fprintln(os.Stderr, res)
+	}
+	dirErr, partial := err.(*engine.DirImportError)
+	if err != nil && !partial {
+		return err
+	}
+	if err = save(eng); err != nil {
+		return err
+	}
+	if partial {
+		return fmt.Errorf("import failed for %d of %d files", dirErr.Failed, len(dirErr.Results))
+	}
+	return nil
+}
+
+// importFrom imports values from file or from stdin if fileName is stdio
+func importFrom(eng *engine.Localizer, fileName, format string) error {
+	if fileName != stdio {
+		return eng.ImportFile(fileName, format)
+	}
+	f, err := engine.FormatByName(defaultFormat(format))
+	if err != nil {
+		return err
+	}
+	return f.Import(eng, os.Stdin)
+}
+
+func defaultFormat(format string) string {
+	if format == "" {
+		return "csv"
+	}
+	return format
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+func memoryCmd(args []string) error {
+	fs := newFlagSet("memory")
+	fs.saveFlags()
+	dryF := fs.Bool("dry-run", false, "only print what would be filled")
+	eng, err := fs.load(args)
+	if err != nil {
+		return err
+	}
+	rep := eng.ApplyTranslationMemory()
+	for _, loc := range eng.Locales[1:] {
+		fmt.Printf("%s: %d filled\n", loc, rep.Filled[loc])
+		for _, c := range rep.Conflicts[loc] {
+			fmt.Printf("%s: conflicting translations of '%s'\n", loc, c)
+		}
+	}
+	if *dryF {
+		return nil
+	}
+	return save(eng)
+}
+
+func syncCmd(args []string) error {
+	fs := newFlagSet("sync")
+	fs.csvFlags()
+	fs.saveFlags()
+	fileF := fs.String("file", "", "`path` to csv file to sync with resources (created if it does not exist)")
+	eng, err := fs.load(args)
+	if err != nil {
+		return err
+	}
+	if err = fs.requireFlag("file", *fileF); err != nil {
+		return err
+	}
+	rep, err := eng.Sync(*fileF)
+	if err != nil {
+		return err
+	}
+	printWarnings(eng.SaveWarnings())
+	fmt.Println(rep)
+	if *fs.verbose {
+		for _, n := range rep.AddedToCSV {
+			fmt.Printf("added to csv: %s\n", n)
+		}
+		for _, n := range rep.RemovedFromCSV {
+			fmt.Printf("removed from csv: %s\n", n)
+		}
+		for _, loc := range eng.Locales {
+			for _, n := range rep.Updated[loc] {
+				fmt.Printf("%s: %s updated\n", loc, n)
+			}
+		}
+	}
+	return nil
+}
+
+func normalizeCmd(args []string) error {
+	fs := newFlagSet("normalize")
+	sortF := fs.String("sort", "alpha", "`order` of elements: alpha, order (kept as they are) or group (related strings together)")
+	groupsF := fs.String("groups", "", "coma-separated name `prefixes` of groups for -sort group in required order (e.g. onboarding_,settings_); others are grouped by part of name before _")
+	indentF := fs.String("indent", "", "indentation of elements: count of spaces or tab (by default indentation of the file is kept)")
+	writeF := fs.Bool("write", false, "rewrite default resources file (only its diff is printed by default)")
+	eng, err := fs.load(args)
+	if err != nil {
+		return err
+	}
+	order, err := engine.ParseRowOrder(*sortF)
+	if err != nil {
+		return err
+	}
+	indent, err := parseIndent(*indentF)
+	if err != nil {
+		return err
+	}
+	opts := engine.NormalizeOptions{Order: order, Indent: indent}
+	if !*writeF {
+		opts.Diff = os.Stdout
+	}
+	return eng.SetRowGroups(splitList(*groupsF)...).SaveDefault(opts)
+}
+
+func initCmd(args []string) error {
+	fs := newFlagSet("init")
+	indentF := fs.String("indent", "", "indentation of created files: count of spaces or tab (by default indentation of existing locale files)")
+	backfillF := fs.Bool("backfill", false, "write default values of missing translations to all the locale files with <!-- TODO: translate --> comment")
+	eng, err := fs.load(args)
+	if err != nil {
+		return err
+	}
+	if err = fs.requireFlag("locales", *fs.locales); err != nil {
+		return err
+	}
+	indent, err := parseIndent(*indentF)
+	if err != nil {
+		return err
+	}
+	eng.SetIndent(indent)
+	for _, loc := range fs.parsedLocales {
+		err = eng.CreateLocale(loc)
+		if errors.Is(err, engine.ErrLocaleExists) {
+			fmt.Printf("%s exists, skipped\n", loc)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s created\n", loc)
+	}
+	if *backfillF {
+		if err = eng.Backfill(); err != nil {
+			return err
+		}
+		printWarnings(eng.SaveWarnings())
+		fmt.Printf("%d files changed\n", len(eng.ChangedFiles()))
+	}
+	return nil
+}
+
+func mergeCmd(args []string) error {
+	fs := newFlagSet("merge")
+	fs.csvFlags()
+	filesF := fs.String("files", "", "coma-separated `paths` to csv files to merge (e.g. filled by different translators)")
+	outF := fs.String("o", "", "`path` to merged csv file")
+	eng, err := fs.load(args)
+	if err != nil {
+		return err
+	}
+	if err = fs.requireFlag("files", *filesF); err != nil {
+		return err
+	}
+	if err = fs.requireFlag("o", *outF); err != nil {
+		return err
+	}
+	return eng.MergeCSV(*outF, splitList(*filesF)...)
+}
+
+func pseudoCmd(args []string) error {
+	fs := newFlagSet("pseudo")
+	fs.saveFlags()
+	localeF := fs.String("locale", "en-rXA", "pseudo-`locale` to generate")
+	expF := fs.Float64("expansion", engine.DefaultPseudoOptions.Expansion, "`ratio` of length added to values")
+	bracketsF := fs.Bool("brackets", engine.DefaultPseudoOptions.Brackets, "wrap values in ⟦ and ⟧")
+	eng, err := fs.load(args)
+	if err != nil {
+		return err
+	}
+	err = eng.GeneratePseudo(*localeF, engine.PseudoOptions{Expansion: *expF, Brackets: *bracketsF})
+	if err != nil {
+		return err
+	}
+	return save(eng)
+}
+
+// parseCoverage parses -min-coverage value returning threshold for all the locales
+// (def if value is empty, -1 if only thresholds by locale are given) and thresholds by locale
+func parseCoverage(s string, def float64) (float64, map[string]float64, error) {
+	if s == "" {
+		return def, nil, nil
+	}
+	min := -1.0
+	thresholds := map[string]float64{}
+	for _, item := range splitList(s) {
+		parts := strings.SplitN(item, "=", 2)
+		pct, err := strconv.ParseFloat(parts[len(parts)-1], 64)
+		if err != nil {
+			return 0, nil, fmt.Errorf("invalid -min-coverage value '%s': %v", item, err)
+		}
+		if len(parts) == 1 {
+			min = pct
+			continue
+		}
+		loc, err := engine.NormalizeLocale(parts[0])
+		if err != nil {
+			return 0, nil, fmt.Errorf("invalid -min-coverage value: %v", err)
+		}
+		thresholds[loc] = pct
+	}
+	return min, thresholds, nil
+}
+
+// save saves resources and prints strings that were not written
+func save(eng *engine.Localizer) error {
+	if err := eng.Save(); err != nil {
+		return err
+	}
+	printWarnings(eng.SaveWarnings())
+	fmt.Printf("%d files changed\n", len(eng.ChangedFiles()))
+	return nil
+}
+
+func printWarnings(errs []error) {
+	for _, e := range errs {
+		fmt.Fprintln(os.Stderr, "warning:", e)
+	}
+}
+
+func identicalCmd(args []string) error {
+	fs := newFlagSet("identical")
+	fs.saveFlags()
+	markF := fs.String("mark", "", "coma-separated `names` of strings to mark as not translatable (e.g. proper nouns)")
+	eng, err := fs.load(args)
+	if err != nil {
+		return err
+	}
+	if *markF != "" {
+		if err = eng.MarkNoTranslate(splitList(*markF)...); err != nil {
+			return err
+		}
+		return save(eng)
+	}
+	for _, n := range eng.IdenticalAcrossLocales() {
+		fmt.Println(n)
+	}
+	return nil
+}
+
+func duplicatesCmd(args []string) error {
+	fs := newFlagSet("duplicates")
+	ignoreCaseF := fs.Bool("ignore-case", false, "compare default values ignoring case and whitespace")
+	minLenF := fs.Int("min-length", 3, "min `length` of values to report (shorter ones like \"OK\" are skipped)")
+	eng, err := fs.load(args)
+	if err != nil {
+		return err
+	}
+	groups := eng.Duplicates(engine.DuplicatesOptions{IgnoreCase: *ignoreCaseF, MinLength: *minLenF})
+	texts := make([]string, 0, len(groups))
+	for t := range groups {
+		texts = append(texts, t)
+	}
+	// groups saving more translations go first
+	sort.Slice(texts, func(i, j int) bool {
+		if len(groups[texts[i]]) != len(groups[texts[j]]) {
+			return len(groups[texts[i]]) > len(groups[texts[j]])
+		}
+		return texts[i] < texts[j]
+	})
+	locales := len(eng.Locales) - 1
+	for _, t := range texts {
+		names := groups[t]
+		fmt.Printf("%d translations saved: %q: %s\n", (len(names)-1)*locales, t, strings.Join(names, ", "))
+	}
+	return nil
+}
+
+func renameCmd(args []string) error {
+	fs := newFlagSet("rename")
+	fs.saveFlags()
+	namesF := fs.String("names", "", "coma-separated `old:new` pairs of names")
+	eng, err := fs.load(args)
+	if err != nil {
+		return err
+	}
+	if err = fs.requireFlag("names", *namesF); err != nil {
+		return err
+	}
+	for _, pair := range splitList(*namesF) {
+		names := strings.SplitN(pair, ":", 2)
+		if len(names) != 2 {
+			return fmt.Errorf("invalid -names value '%s': should be old:new", pair)
+		}
+		if err = eng.Rename(names[0], names[1]); err != nil {
+			return err
+		}
+	}
+	return save(eng)
+}
@@ -0,0 +1,87 @@
+package engine
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	appleStringsFile = "Localizable.strings"
+	appleBaseLocale  = "Base"
+)
+
+var appleStringPlaceholderRegexp = regexp.MustCompile(`%((?:\d+\$)?[-#+ 0,]*\d*(?:\.\d+)?)s`)
+
+// translatableColumn is csv column (true/false) added if non-translatable strings are exported
+const translatableColumn = "translatable"
+
+//SetIncludeNonTranslatable sets whether exports include non-translatable strings: other platforms' formats
+//as they are, csv with additional column 'translatable' (true/false) so the full catalog may be reviewed
+//(values of non-translatable strings are never imported)
+func (l *Localizer) SetIncludeNonTranslatable(include bool) *Localizer {
+	l.includeNonTranslatable = include
+	return l
+}
+
+//ExportAppleStrings writes <locale>.lproj/Localizable.strings files to dir for every locale
+//(default locale goes to Base.lproj); string placeholders (%1$s) are converted to object ones (%1$@)
+func (l *Localizer) ExportAppleStrings(dir string) error {
+	if l.err != nil {
+		return l.err
+	}
+	for _, loc := range l.Locales {
+		lprojDir := filepath.Join(dir, AppleLocale(loc)+".lproj")
+		err := l.mkdirAllOf(lprojDir)
+		if err != nil {
+			return err
+		}
+		err = l.createFile(filepath.Join(lprojDir, appleStringsFile), func(w io.Writer) error {
+			return l.writeAppleStrings(w, loc)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//AppleLocale converts android locale qualifier to apple one (e.g. pt-rBR to pt-BR)
+func AppleLocale(loc string) string {
+	if loc == defLocale {
+		return appleBaseLocale
+	}
+	return LanguageTag(loc)
+}
+
+func (l *Localizer) writeAppleStrings(w io.Writer, loc string) error {
+	for _, n := range l.sortedNames() {
+		s := l.strings[n]
+		if l.isExcluded(n) || !l.isSelected(n) || (!s.Translatable && !l.includeNonTranslatable) {
+			continue
+		}
+		v, ok := s.Values[loc]
+		if s.IsReference() {
+			// apple strings have no references: resolved value is exported
+			v, ok = l.resolveReference(s, loc)
+		}
+		if !ok {
+			continue
+		}
+		_, err := fmt.Fprintf(w, "\"%s\" = \"%s\";\n", escapeApple(n), escapeApple(applePlaceholders(PlainText(v))))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applePlaceholders(v string) string {
+	return appleStringPlaceholderRegexp.ReplaceAllString(v, "%$1@")
+}
+
+func escapeApple(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`).Replace(v)
+}
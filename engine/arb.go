@@ -0,0 +1,194 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	arbFilePrefix = "app_"
+	arbExt        = ".arb"
+	arbLocaleKey  = "@@locale"
+)
+
+type arbPlaceholder struct {
+	Type string `json:"type"`
+}
+
+type arbMeta struct {
+	Description  string                    `json:"description,omitempty"`
+	Placeholders map[string]arbPlaceholder `json:"placeholders,omitempty"`
+}
+
+//ExportARB writes Flutter app_<locale>.arb file to dir for every locale (default locale file is named
+//by default language, see SetDefaultLanguage); printf placeholders are converted to {argN} ones
+//described in @key metadata together with comments of strings, markup tags (<b>, <xliff:g>) are kept
+func (l *Localizer) ExportARB(dir string) error {
+	if l.err != nil {
+		return l.err
+	}
+	err := l.mkdirAllOf(dir)
+	if err != nil {
+		return err
+	}
+	for _, loc := range l.Locales {
+		err = l.createFile(filepath.Join(dir, arbFileName(l.arbLocale(loc))), func(w io.Writer) error {
+			return l.writeARB(w, loc)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//ImportARB imports values from app_<locale>.arb files in dir (default language file and values that are
+//not changed since export are skipped)
+func (l *Localizer) ImportARB(dir string) error {
+	if l.err != nil {
+		return l.err
+	}
+	files, err := l.glob(filepath.Join(dir, arbFilePrefix+"*"+arbExt))
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+	for _, fileName := range files {
+		err = l.importARBFile(fileName)
+		if err != nil {
+			return &FileError{FileName: fileName, Err: err}
+		}
+	}
+	return nil
+}
+
+func (l *Localizer) arbLocale(loc string) string {
+	if loc == defLocale {
+		return l.defaultLanguage()
+	}
+	return strings.Replace(LanguageTag(loc), "-", "_", -1)
+}
+
+func arbFileName(arbLocale string) string {
+	return arbFilePrefix + arbLocale + arbExt
+}
+
+func (l *Localizer) writeARB(w io.Writer, loc string) error {
+	// keys are written in sorted order, so the document is built by hand
+	if _, err := fmt.Fprintf(w, "{\n%s%q: %q", xmlIndent, arbLocaleKey, l.arbLocale(loc)); err != nil {
+		return err
+	}
+	for _, n := range l.sortedNames() {
+		s := l.strings[n]
+		v, ok := s.Values[loc]
+		if !l.isTranslatable(s) || !ok {
+			continue
+		}
+		text, phs := toNamedPlaceholders(markupText(v))
+		if err := writeJSONMember(w, n, text); err != nil {
+			return err
+		}
+		if loc != defLocale {
+			continue
+		}
+		meta := arbMeta{Description: s.Comment}
+		if len(phs) > 0 {
+			meta.Placeholders = map[string]arbPlaceholder{}
+			for _, p := range phs {
+				meta.Placeholders[fmt.Sprintf("arg%d", p.Index)] = arbPlaceholder{Type: arbType(p.Conversion)}
+			}
+		}
+		if meta.Description != "" || meta.Placeholders != nil {
+			if err := writeJSONMember(w, "@"+n, meta); err != nil {
+				return err
+			}
+		}
+	}
+	_, err := io.WriteString(w, "\n}\n")
+	return err
+}
+
+func writeJSONMember(w io.Writer, key string, value interface{}) error {
+	k, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent(xmlIndent, xmlIndent)
+	if err = enc.Encode(value); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, ",\n%s%s: %s", xmlIndent, k, bytes.TrimSpace(buf.Bytes()))
+	return err
+}
+
+func arbType(conversion string) string {
+	switch strings.ToLower(conversion) {
+	case "s":
+		return "String"
+	case "d", "x", "o":
+		return "int"
+	case "f", "e", "g":
+		return "double"
+	}
+	return "Object"
+}
+
+func (l *Localizer) importARBFile(fileName string) error {
+	f, err := l.openFile(fileName)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	arb := map[string]interface{}{}
+	if err = json.NewDecoder(f).Decode(&arb); err != nil {
+		return fmt.Errorf("invalid arb format: %v", err)
+	}
+	arbLoc, _ := arb[arbLocaleKey].(string)
+	if arbLoc == "" {
+		arbLoc = strings.TrimSuffix(strings.TrimPrefix(filepath.Base(fileName), arbFilePrefix), arbExt)
+	}
+	if arbLoc == l.defaultLanguage() {
+		return nil
+	}
+	loc, err := NormalizeLocale(arbLoc)
+	if err != nil {
+		return err
+	}
+	values := map[*String]string{}
+	for k, v := range arb {
+		if strings.HasPrefix(k, "@") || l.isExcluded(k) {
+			continue
+		}
+		s, ok := l.strings[k]
+		if !ok {
+			return &UnknownKeyError{Name: k, Source: "arb"}
+		}
+		if s.IsReference() || !s.Translatable {
+			continue
+		}
+		text, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("value of '%s' is not a string", k)
+		}
+		if exported, _ := toNamedPlaceholders(markupText(s.Values[loc])); text == exported {
+			// unchanged values are kept as they are
+			continue
+		}
+		values[s] = fromNamedPlaceholders(markupValue(text), ParsePlaceholders(s.Values[defLocale]))
+	}
+	l.addLocale(loc)
+	for s, v := range values {
+		if !l.keepsReference(s, loc, v) {
+			s.Values[loc] = l.importedNewlines(v)
+		}
+	}
+	return nil
+}
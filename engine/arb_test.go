@@ -0,0 +1,37 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestARBKeepsMarkup(t *testing.T) {
+	l := loadProject(t, map[string]string{"values/strings.xml": testMarkupDefault, "values-fr/strings.xml": testMarkupFrench})
+	dir := t.TempDir()
+	if err := l.ExportARB(dir); err != nil {
+		t.Fatal(err)
+	}
+	fileName := filepath.Join(dir, "app_fr.arb")
+	content, _ := os.ReadFile(fileName)
+	if !strings.Contains(string(content), `"greeting": "<b>Salut</b> <xliff:g id=\"user\">{arg1}</xliff:g>"`) {
+		t.Errorf("markup is not exported:\n%s", content)
+	}
+	if err := l.ImportARB(dir); err != nil {
+		t.Fatal(err)
+	}
+	for n, v := range map[string]string{"greeting": `<b>Salut</b> <xliff:g id="user">%1$s</xliff:g>`, "quote": `C\'est &lt;bon&gt;`} {
+		if got := l.Strings()[n].Values["fr"]; got != v {
+			t.Errorf("%s: unchanged value became %q", n, got)
+		}
+	}
+
+	replaceInFile(t, fileName, "Salut", "Coucou")
+	if err := l.ImportARB(dir); err != nil {
+		t.Fatal(err)
+	}
+	if fr := l.Strings()["greeting"].Values["fr"]; fr != `<b>Coucou</b> <xliff:g id="user">%1$s</xliff:g>` {
+		t.Errorf("changed value is imported as %q", fr)
+	}
+}
@@ -0,0 +1,64 @@
+package engine
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"path"
+	"strings"
+)
+
+//NewFromArchive creates localization engine reading resources from zip archive (e.g. aar library)
+//that contains res/values*/strings.xml (the shortest res dir is used if there are several of them);
+//archive is read into memory, resources can be exported but not saved (archive filesystem is read-only).
+//Archive and files given by name to Export, Import and others are os files or files of filesystem set by WithFS
+func NewFromArchive(archivePath string, locales ...string) *Localizer {
+	l, _ := NewFromArchiveE(archivePath, WithLocales(locales...))
+	return l
+}
+
+//NewFromArchiveE creates localization engine reading resources from zip archive (see NewFromArchive)
+//and returns error instead of keeping it for the following calls
+func NewFromArchiveE(archivePath string, opts ...Option) (*Localizer, error) {
+	l := &Localizer{fsys: osFS{}}
+	for _, opt := range opts {
+		opt(l)
+	}
+	content, err := l.readFile(archivePath)
+	if err != nil {
+		return errLocalizer(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return errLocalizer(&FileError{FileName: archivePath, Err: err})
+	}
+	root := archiveResourcesDir(zr, l.stringsFileName())
+	if root == "" {
+		return errLocalizer(&FileError{FileName: archivePath,
+			Err: fmt.Errorf("no res/%s/%s is found in archive", valuesDir, l.stringsFileName())})
+	}
+	return NewE(root, append(opts, WithFS(zr), withFilesFS(l.fsys))...)
+}
+
+// archiveResourcesDir returns the shortest path of res dir that contains values*/<stringsFile> in archive
+func archiveResourcesDir(zr *zip.Reader, stringsFile string) string {
+	res := ""
+	for _, f := range zr.File {
+		if path.Base(f.Name) != stringsFile {
+			continue
+		}
+		dir := path.Dir(path.Dir(f.Name))
+		if !strings.HasPrefix(path.Base(path.Dir(f.Name)), valuesDir) || path.Base(dir) != "res" {
+			continue
+		}
+		if res == "" || len(dir) < len(res) {
+			res = dir
+		}
+	}
+	return res
+}
+
+// errLocalizer returns engine with error latch set (see Err)
+func errLocalizer(err error) (*Localizer, error) {
+	return &Localizer{err: err}, err
+}
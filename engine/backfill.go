@@ -0,0 +1,39 @@
+package engine
+
+//TodoNote is comment written before default values copied to locale files by Backfill (a worklist for
+//translators editing xml): values with it that are equal to default values are loaded as missing
+//translations, Save drops the comment as soon as the value is translated
+const TodoNote = "TODO: translate"
+
+//SetBackfill sets whether Save writes default value with TodoNote comment to locale files for every
+//translatable string without translation regardless of untranslated policy (see SetUntranslatedPolicy)
+func (l *Localizer) SetBackfill(backfill bool) *Localizer {
+	l.backfill = backfill
+	return l
+}
+
+//Backfill saves resources with default values of all the missing translations written to locale files
+//with TodoNote comment (so the files contain every key and gaps are reviewable in git)
+func (l *Localizer) Backfill() error {
+	if l.err != nil {
+		return l.err
+	}
+	return l.SetBackfill(true).Save()
+}
+
+// backfilled returns true if value of locale is default value written by Backfill
+func backfilled(s *String, loc string) bool {
+	return s.Notes[loc] == TodoNote && s.Values[loc] == s.Values[defLocale]
+}
+
+// localeNote returns comment written before value of locale: TodoNote for backfilled values, note of
+// locale otherwise (but not TodoNote left before translated value)
+func (l *Localizer) localeNote(s *String, loc string, translated bool) string {
+	if !translated && l.backfill {
+		return TodoNote
+	}
+	if translated && s.Notes[loc] == TodoNote {
+		return ""
+	}
+	return s.Notes[loc]
+}
@@ -0,0 +1,35 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBackfill(t *testing.T) {
+	l := loadProject(t, map[string]string{"values/strings.xml": testDefault, "values-de/strings.xml": testGerman})
+	if err := l.SetUntranslatedPolicy(UntranslatedOmit).Backfill(); err != nil {
+		t.Fatal(err)
+	}
+	got := readProjectFile(t, l, "values-de/strings.xml")
+	if !strings.Contains(got, "<!-- "+TodoNote+" -->\n    <string name=\"bye\">Bye</string>") {
+		t.Errorf("missing key is not backfilled:\n%s", got)
+	}
+	if strings.Contains(got, "app_name") || strings.Count(got, TodoNote) != 1 {
+		t.Errorf("unexpected strings are backfilled:\n%s", got)
+	}
+
+	l = New(l.ResourcesDir).Load()
+	if missing := l.Missing("de"); strings.Join(missing, ",") != "bye" {
+		t.Errorf("missing strings are %v", missing)
+	}
+	if err := l.ImportR(strings.NewReader("id,def,de\nbye,Bye,Tschüss\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Save(); err != nil {
+		t.Fatal(err)
+	}
+	got = readProjectFile(t, l, "values-de/strings.xml")
+	if strings.Contains(got, TodoNote) || !strings.Contains(got, `<string name="bye">Tschüss</string>`) {
+		t.Errorf("translated value is saved as\n%s", got)
+	}
+}
@@ -0,0 +1,115 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+//BackupMode defines how existing resource files are backed up before they are overwritten
+type BackupMode int
+
+const (
+	//BackupSingle copies existing file to <file>.bak replacing previous backup (default)
+	BackupSingle BackupMode = iota
+	//BackupNone overwrites files without backup
+	BackupNone
+	//BackupTimestamped copies existing file to <file>.<yyyymmdd-hhmmss>.bak keeping previous backups
+	BackupTimestamped
+)
+
+const (
+	backupExt             = ".bak"
+	backupTimestampLayout = "20060102-150405"
+)
+
+//ParseBackupMode returns BackupMode by its name: single, none or timestamp
+func ParseBackupMode(name string) (BackupMode, error) {
+	switch name {
+	case "single", "":
+		return BackupSingle, nil
+	case "none":
+		return BackupNone, nil
+	case "timestamp":
+		return BackupTimestamped, nil
+	}
+	return BackupSingle, fmt.Errorf("invalid backup mode '%s': should be single, timestamp or none", name)
+}
+
+//SetBackupMode sets how Save backs up existing locale files
+func (l *Localizer) SetBackupMode(mode BackupMode) *Localizer {
+	l.backupMode = mode
+	return l
+}
+
+// backup backs up existing file according to backup mode
+func (l *Localizer) backup(fileName string) error {
+	if l.backupMode == BackupNone {
+		return nil
+	}
+	content, err := l.readFile(fileName)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	bakName := fileName + backupExt
+	if l.backupMode == BackupTimestamped {
+		bakName = fileName + "." + time.Now().Format(backupTimestampLayout) + backupExt
+	}
+	// the file is copied, not renamed, so it exists until it is replaced with the new one
+	if err = l.writeResourceFile(bakName, content); err != nil {
+		return fmt.Errorf("can not back up %s, file is not overwritten: %v", fileName, err)
+	}
+	return nil
+}
+
+//CleanBackups removes backups of resource files (<file>.bak and <file>.<yyyymmdd-hhmmss>.bak)
+//from values dirs of all the locales
+func (l *Localizer) CleanBackups() error {
+	if l.err != nil {
+		return l.err
+	}
+	for _, loc := range l.Locales {
+		fileName := l.getFileNameForLocale(loc)
+		files, err := l.readDir(filepath.Dir(fileName))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+		for _, f := range files {
+			if f.IsDir() || !isBackupOf(f.Name(), filepath.Base(fileName)) {
+				continue
+			}
+			name := filepath.Join(filepath.Dir(fileName), f.Name())
+			if err = l.remove(name); err != nil {
+				return err
+			}
+			l.logf("%s removed", name)
+		}
+	}
+	return nil
+}
+
+// isBackup returns true if name is name of backup (ends with .bak)
+func isBackup(name string) bool {
+	return strings.HasSuffix(name, backupExt)
+}
+
+// isBackupOf returns true if name is name of backup of file with given name
+func isBackupOf(name, fileName string) bool {
+	if !isBackup(name) || !strings.HasPrefix(name, fileName+".") {
+		return false
+	}
+	stamp := strings.TrimSuffix(strings.TrimPrefix(name, fileName), backupExt)
+	if stamp == "" {
+		return true
+	}
+	_, err := time.Parse(backupTimestampLayout, strings.TrimPrefix(stamp, "."))
+	return err == nil
+}
@@ -0,0 +1,79 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadBOM(t *testing.T) {
+	l := loadProject(t, map[string]string{
+		"values/strings.xml":    "\xEF\xBB\xBF" + testDefault,
+		"values-de/strings.xml": "\xEF\xBB\xBF" + testGerman,
+	})
+	if v := l.Strings()["hello"].Values["de"]; v != "Hallo" {
+		t.Errorf("value is %q", v)
+	}
+}
+
+func TestLoadWindows1251(t *testing.T) {
+	// Привет, мир in windows-1251
+	ru := `<?xml version="1.0" encoding="windows-1251"?>
+<resources>
+    <string name="hello">` + "\xCF\xF0\xE8\xE2\xE5\xF2, \xEC\xE8\xF0" + `</string>
+</resources>
+`
+	l := loadProject(t, map[string]string{"values/strings.xml": testDefault, "values-ru/strings.xml": ru})
+	if v := l.Strings()["hello"].Values["ru"]; v != "Привет, мир" {
+		t.Errorf("value is %q", v)
+	}
+	if err := l.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if got := readProjectFile(t, l, "values-ru/strings.xml"); !strings.Contains(got, ">Привет, мир<") || strings.Contains(got, "windows-1251") {
+		t.Errorf("saved file is\n%s", got)
+	}
+}
+
+func TestLoadUnsupportedEncoding(t *testing.T) {
+	ru := strings.Replace(testGerman, `encoding="utf-8"`, `encoding="koi8-r"`, 1)
+	dir := writeProject(t, map[string]string{"values/strings.xml": testDefault, "values-ru/strings.xml": ru})
+	err := New(dir).Load().Err()
+	if err == nil || !strings.Contains(err.Error(), "unsupported encoding 'koi8-r'") {
+		t.Errorf("load returned %v", err)
+	}
+}
+
+func TestRewriteWindows1251Default(t *testing.T) {
+	// Привет and Пока in windows-1251
+	def := `<?xml version="1.0" encoding="windows-1251"?>
+<resources>
+    <string name="hello">` + "\xCF\xF0\xE8\xE2\xE5\xF2" + `</string>
+    <string name="bye">` + "\xCF\xEE\xEA\xE0" + `</string>
+</resources>
+`
+	want := `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <string name="bye">Пока</string>
+    <string name="hello">Привет</string>
+</resources>
+`
+	l := loadProject(t, map[string]string{"values/strings.xml": def})
+	if err := l.SaveDefault(NormalizeOptions{Order: OrderAlpha}); err != nil {
+		t.Fatal(err)
+	}
+	if got := readProjectFile(t, l, "values/strings.xml"); got != want {
+		t.Errorf("normalized file is\n%s", got)
+	}
+
+	l = loadProject(t, map[string]string{"values/strings.xml": def}).SetWriteDefault(true)
+	if err := l.ImportR(strings.NewReader("id,def\nbye,До свидания\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Save(); err != nil {
+		t.Fatal(err)
+	}
+	got := readProjectFile(t, l, "values/strings.xml")
+	if !strings.Contains(got, `encoding="utf-8"`) || !strings.Contains(got, ">До свидания<") || !strings.Contains(got, ">Привет<") {
+		t.Errorf("default file is saved as\n%s", got)
+	}
+}
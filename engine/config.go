@@ -0,0 +1,119 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+//ConfigFileName is name of project config file (in project dir)
+const ConfigFileName = ".localizer.json"
+
+//Config contains project defaults read by New from .localizer.json in project dir;
+//options set by engine's methods (and flags of commands) override them
+type Config struct {
+	//ResourcesDir is path of resources dir relative to project dir (app/src/main/res or project dir itself by default)
+	ResourcesDir string `json:"resourcesDir"`
+	//Locales are required locales (used if no locales are given to New)
+	Locales []string `json:"locales"`
+	//Exclude contains name patterns of strings to leave out of processing (see SetExcludePatterns)
+	Exclude []string `json:"exclude"`
+	//ExcludeFiles contains name globs of resource files strings of which are never translated (see SetExcludeFiles)
+	ExcludeFiles []string `json:"excludeFiles"`
+	//Backup is backup mode: single, timestamp or none
+	Backup string `json:"backup"`
+	//StringsFile is name of resource files in values dirs
+	StringsFile string `json:"stringsFile"`
+	//Delimiter is delimiter of csv files (e.g. ";")
+	Delimiter string `json:"delimiter"`
+	//Module is gradle module resources of which are processed (see SetModule)
+	Module string `json:"module"`
+	//NormalizeWhitespace enables whitespace normalization of values (see SetNormalizeWhitespace)
+	NormalizeWhitespace bool `json:"normalizeWhitespace"`
+	//PreserveWhitespace contains name patterns of strings which whitespace is not normalized
+	PreserveWhitespace []string `json:"preserveWhitespace"`
+	//CanonicalLocales keeps locales in canonical order (see SetCanonicalLocales)
+	CanonicalLocales bool `json:"canonicalLocales"`
+}
+
+// loadConfig reads project config file (if any) and applies it
+func (l *Localizer) loadConfig() {
+	fileName := filepath.Join(l.projectDir, ConfigFileName)
+	content, err := l.readFile(fileName)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			l.configErr = &FileError{FileName: fileName, Err: err}
+		}
+		return
+	}
+	cfg := Config{}
+	if err = json.Unmarshal(content, &cfg); err == nil {
+		err = l.applyConfig(cfg)
+	}
+	if err != nil {
+		l.configErr = &FileError{FileName: fileName, Err: err}
+	}
+}
+
+func (l *Localizer) applyConfig(cfg Config) error {
+	if cfg.Backup != "" {
+		mode, err := ParseBackupMode(cfg.Backup)
+		if err != nil {
+			return err
+		}
+		l.backupMode = mode
+	}
+	if cfg.Delimiter != "" {
+		d, size := utf8.DecodeRuneInString(cfg.Delimiter)
+		if size != len(cfg.Delimiter) {
+			return fmt.Errorf("invalid delimiter '%s': should be single character", cfg.Delimiter)
+		}
+		l.comma = d
+	}
+	for i, loc := range cfg.Locales {
+		norm, err := NormalizeLocale(loc)
+		if err != nil {
+			return err
+		}
+		cfg.Locales[i] = norm
+	}
+	if len(l.requestedLocales) == 0 {
+		l.requestedLocales = cfg.Locales
+	}
+	// options given to NewE override config
+	if l.exclude == nil {
+		l.exclude = cfg.Exclude
+	}
+	if l.excludeFiles == nil {
+		l.excludeFiles = cfg.ExcludeFiles
+	}
+	if l.StringsFileName == "" {
+		l.StringsFileName = cfg.StringsFile
+	}
+	if l.module == "" {
+		l.module = strings.TrimPrefix(cfg.Module, ":")
+	}
+	if l.preserveWhitespace == nil {
+		l.preserveWhitespace = cfg.PreserveWhitespace
+	}
+	l.normalizeWhitespace = l.normalizeWhitespace || cfg.NormalizeWhitespace
+	l.canonicalLocales = l.canonicalLocales || cfg.CanonicalLocales
+	l.configResourcesDir = cfg.ResourcesDir
+	return nil
+}
+
+//SetDelimiter sets delimiter of csv files (comma by default)
+func (l *Localizer) SetDelimiter(d rune) *Localizer {
+	l.comma = d
+	return l
+}
+
+func (l *Localizer) delimiter() rune {
+	if l.comma == 0 {
+		return ','
+	}
+	return l.comma
+}
@@ -0,0 +1,58 @@
+package engine
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestConfigDefaults(t *testing.T) {
+	dir := writeProject(t, map[string]string{
+		ConfigFileName:           `{"resourcesDir": "res", "locales": ["de", "pt-BR"], "delimiter": ";", "exclude": ["bye"]}`,
+		"res/values/strings.xml": testDefault,
+	})
+	l := New(dir).Load()
+	if err := l.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(l.Locales, ","); got != "def,de,pt-rBR" {
+		t.Errorf("locales are %s", got)
+	}
+	buf := &bytes.Buffer{}
+	if err := l.ExportW(buf); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); !strings.HasPrefix(got, "id;def;de;pt-rBR\n") || strings.Contains(got, "bye") {
+		t.Errorf("export is\n%s", got)
+	}
+}
+
+func TestOptionsOverrideConfig(t *testing.T) {
+	dir := writeProject(t, map[string]string{
+		ConfigFileName:           `{"resourcesDir": "res", "locales": ["de"], "stringsFile": "other.xml"}`,
+		"res/values/strings.xml": testDefault,
+	})
+	l, err := NewE(dir, WithLocales("fr"), WithStringsFileName("strings.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = l.LoadE(); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(l.Locales, ","); got != "def,fr" {
+		t.Errorf("locales are %s", got)
+	}
+	if _, ok := l.Strings()["hello"]; !ok {
+		t.Error("strings file of options is not loaded")
+	}
+}
+
+func TestInvalidConfig(t *testing.T) {
+	dir := writeProject(t, map[string]string{
+		ConfigFileName:       `{"delimiter": ";;"}`,
+		"values/strings.xml": testDefault,
+	})
+	if _, err := NewE(dir); err == nil || !strings.Contains(err.Error(), ConfigFileName) {
+		t.Errorf("error is %v", err)
+	}
+}
@@ -0,0 +1,187 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+)
+
+var translatableAttrRegexp = regexp.MustCompile(`\s+translatable\s*=\s*"[^"]*"`)
+
+//SetWriteDefault sets whether Save writes default resources file (values/strings.xml) too
+//and imports take values of default locale; the file is updated in place: order, formatting,
+//comments and other elements (plurals and string arrays too) are kept, changed values of strings are replaced
+//and new strings are appended
+func (l *Localizer) SetWriteDefault(write bool) *Localizer {
+	l.writeDefault = write
+	return l
+}
+
+// saveDefault updates default resources file with values of engine's strings
+func (l *Localizer) saveDefault() error {
+	fileName := l.getFileNameForLocale(defLocale)
+	content, err := l.readFile(fileName)
+	if err != nil {
+		return err
+	}
+	updated, err := l.updateDefault(content)
+	if err != nil {
+		return &FileError{FileName: fileName, Err: err}
+	}
+	if bytes.Equal(updated, content) {
+		return nil
+	}
+	return l.writeContent(fileName, updated)
+}
+
+// updateDefault returns content of default resources file with string elements replaced by engine's strings:
+// unchanged elements are kept byte by byte, removed ones are dropped and new ones are added before </resources>
+func (l *Localizer) updateDefault(content []byte) ([]byte, error) {
+	content, err := utf8Content(content)
+	if err != nil {
+		return nil, err
+	}
+	d := xml.NewDecoder(bytes.NewReader(content))
+	out := bytes.Buffer{}
+	written := map[string]bool{}
+	indent := ""
+	// content before last is already copied to out
+	last := int64(0)
+	depth := 0
+	for {
+		start := d.InputOffset()
+		t, err := d.Token()
+		if err == io.EOF {
+			return nil, fmt.Errorf("closing </resources> not found")
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := t.(type) {
+		case xml.StartElement:
+			depth++
+			if depth != 2 || t.Name.Local != "string" {
+				continue
+			}
+			tagEnd := d.InputOffset()
+			if err = d.Skip(); err != nil {
+				return nil, err
+			}
+			depth--
+			end := d.InputOffset()
+			out.Write(content[last:start])
+			last = end
+			if indent == "" {
+				indent = string(lineIndent(out.Bytes()))
+			}
+			name := ""
+			for _, a := range t.Attr {
+				if a.Name.Local == "name" {
+					name = a.Value
+				}
+			}
+			tag := content[start:tagEnd]
+			if newName, ok := l.renames[name]; ok {
+				name = newName
+				tag = nameAttrRegexp.ReplaceAll(tag, []byte(`name="`+newName+`"`))
+			}
+			s, ok := l.strings[name]
+			if !ok {
+				// removed string: its line is dropped
+				out.Truncate(out.Len() - len(lineIndent(out.Bytes())))
+				last += int64(len(newlineAt(content[end:])))
+				continue
+			}
+			written[name] = true
+			out.Write(defaultElement(s, tag, content[tagEnd:end]))
+		case xml.EndElement:
+			depth--
+			if depth > 0 {
+				continue
+			}
+			out.Write(content[last:start])
+			last = start
+			pending := lineIndent(out.Bytes())
+			out.Truncate(out.Len() - len(pending))
+			if indent == "" {
+				indent = l.indent
+			}
+			if indent == "" {
+				indent = xmlIndent
+			}
+			for _, n := range l.sortedNames() {
+				s := l.strings[n]
+				if _, ok := s.Values[defLocale]; written[n] || !ok {
+					continue
+				}
+				if _, _, item := splitItemName(n); item {
+					// plurals and string arrays of default file are kept as they are
+					continue
+				}
+				if s.Comment != "" {
+					fmt.Fprintf(&out, "%s%s\n", indent, xmlComment(s.Comment))
+				}
+				fmt.Fprintf(&out, "%s%s\n", indent, newDefaultElement(s))
+			}
+			out.Write(pending)
+			out.Write(content[last:])
+			return out.Bytes(), nil
+		}
+	}
+}
+
+// defaultElement returns string element with start tag and content (inner xml with end tag) updated by s
+func defaultElement(s *String, tag, content []byte) []byte {
+	value := escapeAmpersands(s.Values[defLocale])
+	selfClosing := bytes.HasSuffix(tag, []byte("/>"))
+	inner := []byte{}
+	if !selfClosing {
+		inner = content[:bytes.LastIndex(content, []byte("</"))]
+	}
+	noTranslate := bytes.Contains(translatableAttrRegexp.Find(tag), []byte(`"false"`))
+	if string(inner) == value && noTranslate == !s.Translatable {
+		return append(append([]byte{}, tag...), content...)
+	}
+	if noTranslate != !s.Translatable {
+		if s.Translatable {
+			tag = translatableAttrRegexp.ReplaceAll(tag, nil)
+		} else {
+			tag = setNoTranslate(tag)
+		}
+	}
+	if selfClosing {
+		tag = bytes.TrimRight(tag[:len(tag)-2], " ")
+	}
+	res := append([]byte{}, tag...)
+	if selfClosing {
+		res = append(res, '>')
+	}
+	res = append(res, value...)
+	return append(res, "</string>"...)
+}
+
+func newDefaultElement(s *String) string {
+	attrs := ""
+	if !s.Translatable {
+		attrs = ` translatable="false"`
+	}
+	return fmt.Sprintf(`<string name="%s"%s>%s</string>`, s.Name, attrs, escapeAmpersands(s.Values[defLocale]))
+}
+
+// lineIndent returns trailing spaces and tabs of b (indentation of element starting after it)
+func lineIndent(b []byte) []byte {
+	return b[len(bytes.TrimRight(b, " \t")):]
+}
+
+// newlineAt returns line break b starts with (if any)
+func newlineAt(b []byte) []byte {
+	if bytes.HasPrefix(b, []byte("\r\n")) {
+		return b[:2]
+	}
+	if bytes.HasPrefix(b, []byte("\n")) {
+		return b[:1]
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package engine
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+// deltaColumn is csv column of ExportDelta telling why the row is exported; it is ignored on import
+const deltaColumn = "delta"
+
+// kinds of rows of ExportDelta
+const (
+	deltaNew     = "new"
+	deltaChanged = "changed"
+	deltaCleared = "cleared"
+)
+
+//SetDeltaCleared sets whether ExportDelta also exports strings translations of which were cleared
+//since previous export
+func (l *Localizer) SetDeltaCleared(cleared bool) *Localizer {
+	l.deltaCleared = cleared
+	return l
+}
+
+//ExportDelta writes in csv format only strings that are new or whose default value changed since
+//previous csv export read from previous (and strings with cleared translations, see SetDeltaCleared);
+//rows have delta column with new, changed or cleared which is ignored on import
+func (l *Localizer) ExportDelta(current io.Writer, previous io.Reader) error {
+	if l.err != nil {
+		return l.err
+	}
+	prev, err := l.readPrevious(previous)
+	if err != nil {
+		return fmt.Errorf("previous export: %w", err)
+	}
+	l.delta = map[string]string{}
+	defer func() {
+		l.delta = nil
+	}()
+	for n, s := range l.strings {
+		if !l.isTranslatable(s) {
+			continue
+		}
+		p, ok := prev[n]
+		switch {
+		case !ok:
+			l.delta[n] = deltaNew
+		case p[defLocale] != editableValue(s.Values[defLocale]) && p[defLocale] != s.Values[defLocale]:
+			l.delta[n] = deltaChanged
+		case l.deltaCleared:
+			for loc, v := range p {
+				if loc != defLocale && v != "" && contains(l.Locales[1:], loc) && !s.IsTranslated(loc) {
+					l.delta[n] = deltaCleared
+				}
+			}
+		}
+	}
+	return l.writeCSVFiltered(current, l.delimiter(), l.Locales[1:], false, func(s *String) bool {
+		return l.delta[s.Name] != ""
+	})
+}
+
+//ExportDeltaFile exports to csv file strings changed since previous csv export in file previous
+//(see ExportDelta); files whose names end with .gz are compressed
+func (l *Localizer) ExportDeltaFile(fileName, previous string) error {
+	return l.withPrevious(previous, func(prev io.Reader) error {
+		return l.exportFile(fileName, func(w io.Writer) error {
+			return l.ExportDelta(w, prev)
+		})
+	})
+}
+
+//ExportDeltaOf writes in csv format strings changed since previous csv export in file previous (see ExportDelta)
+func (l *Localizer) ExportDeltaOf(current io.Writer, previous string) error {
+	return l.withPrevious(previous, func(prev io.Reader) error {
+		return l.ExportDelta(current, prev)
+	})
+}
+
+// withPrevious calls f with content of export file previous (decompressed if name ends with .gz)
+func (l *Localizer) withPrevious(previous string, f func(prev io.Reader) error) error {
+	if l.err != nil {
+		return l.err
+	}
+	prev, err := l.openImportFile(previous)
+	if err != nil {
+		return err
+	}
+	defer prev.Close()
+	return f(prev)
+}
+
+// readPrevious reads values of csv export by names and locales (def for default values)
+func (l *Localizer) readPrevious(r io.Reader) (map[string]map[string]string, error) {
+	cr := csv.NewReader(r)
+	cr.Comma = l.delimiter()
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+	if l.mapping != nil {
+		header = l.mapping.importHeader(header)
+	}
+	nameCol := -1
+	columns := map[int]string{}
+	for i, h := range header {
+		if h == nameColumn {
+			nameCol = i
+		} else if h == defLocale {
+			columns[i] = h
+		} else if loc, ok := l.columnLocale(h); ok {
+			columns[i] = loc
+		}
+	}
+	if nameCol < 0 {
+		return nil, fmt.Errorf("%w: column '%s' is not found", ErrInvalidHeader, nameColumn)
+	}
+	res := map[string]map[string]string{}
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			return res, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if nameCol >= len(row) {
+			continue
+		}
+		values := map[string]string{}
+		for i, loc := range columns {
+			if i < len(row) {
+				values[loc] = row[i]
+			}
+		}
+		res[row[nameCol]] = values
+	}
+}
@@ -0,0 +1,49 @@
+package engine
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportDeltaFile(t *testing.T) {
+	l := loadProject(t, map[string]string{"values/strings.xml": testDefault})
+	dir := t.TempDir()
+	// previous export is read decompressed and delta is written compressed
+	previous := filepath.Join(dir, "previous.csv.gz")
+	if err := l.exportFile(previous, func(w io.Writer) error {
+		_, err := io.WriteString(w, "id,def\nhello,Hello\nbye,Good bye\n")
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	delta := filepath.Join(dir, "delta.csv.gz")
+	if err := l.ExportDeltaFile(delta, previous); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(delta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "bye,Bye") || strings.Contains(string(content), "hello") {
+		t.Errorf("delta is:\n%s", content)
+	}
+	if err := l.ExportDeltaFile(filepath.Join(dir, "other.csv"), filepath.Join(dir, "missing.csv")); !os.IsNotExist(err) {
+		t.Errorf("error is %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "other.csv")); !os.IsNotExist(err) {
+		t.Error("delta is written without previous export")
+	}
+}
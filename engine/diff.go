@@ -0,0 +1,152 @@
+package engine
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// diffContext is count of unchanged lines around changes in diff hunks
+const diffContext = 3
+
+// diffLine is line of diff: ' ' (unchanged), '-' (removed) or '+' (added)
+type diffLine struct {
+	op   byte
+	text []byte
+}
+
+// writeDiff writes unified diff of old and updated content of file to w (nothing if they are equal)
+func writeDiff(w io.Writer, fileName string, old, updated []byte) error {
+	if bytes.Equal(old, updated) {
+		return nil
+	}
+	lines := diffLines(splitLines(old), splitLines(updated))
+	if _, err := fmt.Fprintf(w, "--- %s\n+++ %s\n", fileName, fileName); err != nil {
+		return err
+	}
+	for start := 0; start < len(lines); {
+		// hunk starts diffContext lines before the next change and ends when there are no changes
+		// in 2*diffContext following lines
+		first := start
+		for first < len(lines) && lines[first].op == ' ' {
+			first++
+		}
+		if first == len(lines) {
+			break
+		}
+		from := first - diffContext
+		if from < start {
+			from = start
+		}
+		to, unchanged := first, 0
+		for to < len(lines) && unchanged <= 2*diffContext {
+			if lines[to].op == ' ' {
+				unchanged++
+			} else {
+				unchanged = 0
+			}
+			to++
+		}
+		if unchanged > diffContext {
+			to -= unchanged - diffContext
+		}
+		if err := writeHunk(w, lines, from, to); err != nil {
+			return err
+		}
+		start = to
+	}
+	return nil
+}
+
+// writeHunk writes lines[from:to] with @@ header giving their positions in old and new content
+func writeHunk(w io.Writer, lines []diffLine, from, to int) error {
+	oldStart, newStart := 1, 1
+	for _, l := range lines[:from] {
+		if l.op != '+' {
+			oldStart++
+		}
+		if l.op != '-' {
+			newStart++
+		}
+	}
+	oldCount, newCount := 0, 0
+	for _, l := range lines[from:to] {
+		if l.op != '+' {
+			oldCount++
+		}
+		if l.op != '-' {
+			newCount++
+		}
+	}
+	if _, err := fmt.Fprintf(w, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount); err != nil {
+		return err
+	}
+	for _, l := range lines[from:to] {
+		if _, err := fmt.Fprintf(w, "%c%s\n", l.op, l.text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// diffLines returns lines of a and b marked by longest common subsequence of them
+func diffLines(a, b [][]byte) []diffLine {
+	// common prefix and suffix are not compared by lcs table
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && bytes.Equal(a[prefix], b[prefix]) {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && bytes.Equal(a[len(a)-1-suffix], b[len(b)-1-suffix]) {
+		suffix++
+	}
+	var res []diffLine
+	for _, line := range a[:prefix] {
+		res = append(res, diffLine{' ', line})
+	}
+	ma, mb := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	// lcs[i][j] is length of lcs of ma[i:] and mb[j:]
+	lcs := make([][]int32, len(ma)+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, len(mb)+1)
+	}
+	for i := len(ma) - 1; i >= 0; i-- {
+		for j := len(mb) - 1; j >= 0; j-- {
+			if bytes.Equal(ma[i], mb[j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < len(ma) || j < len(mb) {
+		switch {
+		case i < len(ma) && j < len(mb) && bytes.Equal(ma[i], mb[j]):
+			res = append(res, diffLine{' ', ma[i]})
+			i++
+			j++
+		case j == len(mb) || (i < len(ma) && lcs[i+1][j] >= lcs[i][j+1]):
+			res = append(res, diffLine{'-', ma[i]})
+			i++
+		default:
+			res = append(res, diffLine{'+', mb[j]})
+			j++
+		}
+	}
+	for _, line := range a[len(a)-suffix:] {
+		res = append(res, diffLine{' ', line})
+	}
+	return res
+}
+
+// splitLines splits content into lines without line breaks
+func splitLines(content []byte) [][]byte {
+	lines := bytes.Split(bytes.TrimSuffix(content, []byte("\n")), []byte("\n"))
+	for i, line := range lines {
+		lines[i] = bytes.TrimSuffix(line, []byte("\r"))
+	}
+	return lines
+}
@@ -0,0 +1,50 @@
+package engine
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+//DuplicatesOptions contains options of Duplicates
+type DuplicatesOptions struct {
+	//IgnoreCase enables comparing values ignoring case and whitespace differences
+	IgnoreCase bool
+	//MinLength is min length (in characters of plain text) of values to be reported (e.g. to skip "OK")
+	MinLength int
+}
+
+//Duplicates returns names of translatable strings that have the same default value
+//(candidates for merging into shared resources) by the value; names are sorted
+func (l *Localizer) Duplicates(opts ...DuplicatesOptions) map[string][]string {
+	res := map[string][]string{}
+	if l.err != nil {
+		return res
+	}
+	o := DuplicatesOptions{}
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	groups := map[string][]string{}
+	texts := map[string]string{}
+	for _, n := range l.sortedNames() {
+		s := l.strings[n]
+		v := s.Values[defLocale]
+		if !l.isTranslatable(s) || utf8.RuneCountInString(strings.TrimSpace(PlainText(v))) < o.MinLength || v == "" {
+			continue
+		}
+		key := v
+		if o.IgnoreCase {
+			key = strings.ToLower(strings.Join(strings.Fields(v), " "))
+		}
+		if _, ok := texts[key]; !ok {
+			texts[key] = v
+		}
+		groups[key] = append(groups[key], n)
+	}
+	for key, names := range groups {
+		if len(names) > 1 {
+			res[texts[key]] = names
+		}
+	}
+	return res
+}
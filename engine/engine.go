@@ -1,14 +1,21 @@
 package engine
 
 import (
+	"bytes"
 	"encoding/csv"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
-	"io/ioutil"
+	"io/fs"
+	"log"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 )
 
 const (
@@ -16,58 +23,237 @@ const (
 	stringsFile = "strings.xml"
 	valuesDir   = "values"
 	nameColumn  = "id"
+	contextMark = "#"
+	noteColumn  = "note"
 	xmlIndent   = "  "
+	// modes of created dirs and files (existing files keep their mode)
+	dirMode  = 0755
+	fileMode = 0644
+	// max count of resource files read at once
+	loadWorkers = 8
 )
 
 type xStrings struct {
-	XMLName xml.Name  `xml:"resources"`
-	Strings []xString `xml:"string"`
+	XMLName xml.Name `xml:"resources"`
+	// Attrs contains attributes of root element (e.g. xmlns:tools declaration) named as in file
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Strings []xString  `xml:"string"`
 }
 
+// Value keeps raw inner xml of the element (entities are not decoded, markup is preserved)
+// so values are written back exactly as they were read
 type xString struct {
 	Name         string `xml:"name,attr"`
-	Value        string `xml:",chardata"`
+	Value        string `xml:",innerxml"`
 	Translatable string `xml:"translatable,attr,omitempty"`
+	// Attrs contains other attributes (e.g. tools:ignore) named as in file
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Comment string     `xml:"-"`
+	// copied is true if value is written in place of missing translation (see State.Copied)
+	copied bool
 }
 
-//String contains all the strings of project
+//String contains all the strings of project;
+//values are kept as raw xml content of the element (e.g. "Tom &amp; Jerry", "<b>bold</b>")
 type String struct {
 	Name         string
 	Values       map[string]string
 	Translatable bool
+	//Comment is comment preceding the string in default resources file (description for translators)
+	Comment string
+	//Status contains statuses of values by locale (e.g. StatusMachine)
+	Status map[string]string
+	//Notes contains reviewers' notes by locale (comments preceding the string in locale files)
+	Notes map[string]string
+	// attrs contains other attributes of elements (e.g. tools:ignore) by locale
+	attrs map[string][]xml.Attr
+	// order is index of the string in resource files (strings of default resources file go first)
+	order int
+}
+
+//IsTranslated returns true if string has non-empty value for locale; values Save writes to locale files
+//in place of missing translations (see SetUntranslatedPolicy) are loaded as missing ones (see State.Copied),
+//so translation equal to default value (e.g. "OK") is translated
+func (s *String) IsTranslated(loc string) bool {
+	return s.Values[loc] != ""
+}
+
+//SetStatus sets status of value for locale (e.g. StatusApproved; empty status removes it)
+func (s *String) SetStatus(loc, status string) {
+	if status == "" {
+		delete(s.Status, loc)
+		return
+	}
+	if s.Status == nil {
+		s.Status = map[string]string{}
+	}
+	s.Status[loc] = status
 }
 
 //Localizer contains localization engine data
 type Localizer struct {
 	ResourcesDir string
 	Locales      []string
-	strings      map[string]*String
-	err          error
+	//StringsFileName is name of resource files in values dirs (see SetStringsFileName)
+	StringsFileName string
+	strings         map[string]*String
+	err             error
+
+	fsys fs.FS
+	// filesFS is filesystem of files given by name to Export, Import and others (see files)
+	filesFS          fs.FS
+	projectDir       string
+	requestedLocales []string
+	// localeSources contains sources of locales (see LocaleSource)
+	localeSources map[string]LocaleSource
+	// configResourcesDir is resources dir set in project config relative to project dir
+	configResourcesDir string
+	configErr          error
+	// defaultDir is values dir of default locale: values or qualified one (e.g. values-en) if there is no bare values
+	defaultDir string
+
+	unusedWhitelist []string
+	icuPatterns     []string
+	maxExpansion    float64
+	exclude         []string
+	excludeFiles    []string
+	ignorePatterns  []string
+	excludedNames   map[string]bool
+	keyPrefix       string
+	keyPattern      *regexp.Regexp
+	backupMode      BackupMode
+	logger          *log.Logger
+	lastImport      *ImportSummary
+	importLocales   []string
+	lastDirImport   []*FileImportResult
+	warnings        []error
+	saveWarnings    []error
+	strict          bool
+	fallback        FallbackFunc
+	fallbacks       map[string]string
+	untranslated    UntranslatedPolicy
+	skipEmptyCells  bool
+	comma           rune
+	defLanguage     string
+	indent          string
+	propertiesUTF8  bool
+	mapping         *Mapping
+	module          string
+	rowOrder        RowOrder
+	rowGroup        func(name string) string
+	rowGroupOrder   []string
+	transform       TransformFunc
+	pseudoLocale    string
+	pseudoOptions   []PseudoOptions
+	// loadedFiles contains names of resource files read by Load by locale
+	loadedFiles map[string]string
+	// rootAttrs contains attributes of <resources> element (namespace declarations) of loaded files by locale
+	rootAttrs map[string][]xml.Attr
+	// changedFiles contains paths of files written by the last Save
+	changedFiles []string
+	// preserveWhitespace contains name patterns of strings which whitespace is not normalized
+	preserveWhitespace []string
+	// delta contains kinds of rows exported by ExportDelta by names
+	delta map[string]string
+
+	includeNonTranslatable bool
+	reviewIncomplete       bool
+	exportReferences       bool
+	exportArguments        bool
+	normalizeWhitespace    bool
+	normalizeNewlines      bool
+	backfill               bool
+	canonicalLocales       bool
+	keepNonTranslatable    bool
+	overwriteReferences    bool
+	transformExports       bool
+	onlyMissing            bool
+	writeDefault           bool
+	deltaCleared           bool
+	jsonPlaceholders       PlaceholderStyle
+	// printfPlaceholders contains placeholders of default values before conversion to named style
+	printfPlaceholders map[string][]Placeholder
+	// state keeps records of translations (see StaleTranslations)
+	state *State
+	// renames maps names in default resources file to new ones (see Rename)
+	renames map[string]string
 }
 
-//New creates new localization engine
+//New creates new localization engine; locales found in resources dir are added after given ones;
+//defaults are taken from project config file if there is one (see Config);
+//errors are returned by the following calls (see NewE)
 func New(projectDir string, locales ...string) *Localizer {
-	l := &Localizer{Locales: []string{defLocale}}
-	resPath := filepath.Join(projectDir, "app/src/main/res")
-	l.err = checkPathIsResourcesDir(resPath)
-	if l.err != nil {
-		resPath = projectDir
-		if checkPathIsResourcesDir(resPath) != nil {
-			return l
+	l, _ := NewE(projectDir, WithLocales(locales...))
+	return l
+}
+
+//SetStringsFileName sets name of resource files in values dirs (strings.xml by default)
+//and looks for resources dir and locales again
+func (l *Localizer) SetStringsFileName(name string) *Localizer {
+	l.StringsFileName = name
+	l.init()
+	return l
+}
+
+// init looks for resources dir in project and guesses locales
+func (l *Localizer) init() {
+	if l.err = l.configErr; l.err != nil {
+		return
+	}
+	l.Locales = []string{defLocale}
+	l.localeSources = map[string]LocaleSource{}
+	l.ResourcesDir = ""
+	resPath := filepath.Join(l.projectDir, "app/src/main/res")
+	if l.configResourcesDir != "" {
+		resPath = filepath.Join(l.projectDir, l.configResourcesDir)
+	}
+	var dir, rel string
+	var err error
+	if l.module != "" && l.configResourcesDir == "" {
+		// resources dir of module is looked for in gradle configuration only
+		if rel, dir, l.err = l.discoveredResourcesDir(); l.err != nil {
+			return
+		}
+		resPath = filepath.Join(l.projectDir, rel)
+	} else {
+		dir, err = l.checkPathIsResourcesDir(resPath)
+	}
+	if err != nil && l.configResourcesDir != "" {
+		l.err = err
+		return
+	}
+	if err != nil {
+		var e error
+		if rel, dir, e = l.discoveredResourcesDir(); e == nil {
+			resPath, err = filepath.Join(l.projectDir, rel), nil
+		} else if e != errNoDiscoveredResources {
+			l.err = e
+			return
+		}
+	}
+	if err != nil {
+		tried := resPath
+		resPath = l.projectDir
+		var e error
+		if dir, e = l.checkPathIsResourcesDir(resPath); e != nil {
+			l.err = fmt.Errorf("no resources dir with %s found; looked at %s and %s: %w",
+				filepath.Join(valuesDir, l.stringsFileName()), tried, resPath, err)
+			return
 		}
 	}
+	l.err = nil
 	l.ResourcesDir = resPath
-	if len(locales) > 0 {
-		l.addLocales(locales)
-	} else {
-		l.guessLocales()
+	l.defaultDir = dir
+	// explicitly given locales keep their order; found ones that are not given are appended
+	for _, loc := range l.requestedLocales {
+		l.addLocaleFrom(loc, LocaleRequested)
 	}
-	return l
+	l.guessLocales()
 }
 
-//AddLocale adds locale to localizer
+//AddLocale adds locale to localizer (as requested one, see LocaleSource)
 func (l *Localizer) AddLocale(loc string) *Localizer {
-	l.addLocale(loc)
+	l.addLocaleFrom(loc, LocaleRequested)
 	return l
 }
 
@@ -77,67 +263,247 @@ func (l *Localizer) Load() *Localizer {
 		return l
 	}
 	l.strings = map[string]*String{}
-	for _, loc := range l.Locales {
-		fileName := l.getFileNameForLocale(loc, false)
-		rf, err := l.readResources(fileName)
-		if err != nil {
-			l.err = err
-			return l
+	l.warnings = nil
+	l.renames = nil
+	l.loadedFiles = map[string]string{}
+	l.rootAttrs = map[string][]xml.Attr{}
+	l.loadExclusions()
+	files := l.readAllResources()
+	var errs LoadErrors
+	for i, loc := range l.Locales {
+		if files[i].err != nil {
+			if os.IsNotExist(files[i].err) && loc != defLocale && l.LocaleSource(loc) != LocaleGuessed {
+				// locale was added explicitly and has no resources yet
+				continue
+			}
+			errs = append(errs, &FileError{FileName: l.getFileNameForLocale(loc), Err: files[i].err})
 		}
-		for _, r := range rf.Strings {
+	}
+	if len(errs) > 0 {
+		l.err = errs
+		return l
+	}
+	// state is needed to recognize values written in place of missing translations
+	state, err := l.loadState()
+	if err != nil {
+		l.warnings = append(l.warnings, &FileError{FileName: l.StateFileName(), Err: err})
+	}
+	l.state = state
+	for i, loc := range l.Locales {
+		if files[i].res == nil {
+			continue
+		}
+		l.loadedFiles[loc] = l.getFileNameForLocale(loc)
+		l.rootAttrs[loc] = files[i].res.Attrs
+		defined := map[string]string{}
+		for _, r := range files[i].res.Strings {
+			if v, ok := defined[r.Name]; ok {
+				l.warnings = append(l.warnings, &DuplicateError{FileName: l.getFileNameForLocale(loc), Name: r.Name, First: v, Second: r.Value})
+			}
+			defined[r.Name] = r.Value
 			s, ok := l.strings[r.Name]
 			if !ok {
-				s = &String{Name: r.Name, Values: map[string]string{}, Translatable: true}
+				s = &String{Name: r.Name, Values: map[string]string{}, Translatable: true, order: len(l.strings)}
 				l.strings[r.Name] = s
 			}
-			s.Values[loc] = r.Value
+			s.Values[loc] = l.normalizedValue(r.Name, r.Value)
+			if len(r.Attrs) > 0 {
+				if s.attrs == nil {
+					s.attrs = map[string][]xml.Attr{}
+				}
+				s.attrs[loc] = r.Attrs
+			}
+			if loc == defLocale {
+				s.Comment = r.Comment
+			} else {
+				s.SetNote(loc, r.Comment)
+				if backfilled(s, loc) || l.isCopy(r.Name, loc, r.Value) {
+					delete(s.Values, loc)
+				}
+			}
 			if r.Translatable == "false" {
 				s.Translatable = false
 			}
 		}
 	}
+	l.warnings = append(l.warnings, l.NonTranslatableValues()...)
+	l.applyStatuses()
+	if l.strict && len(l.warnings) > 0 {
+		l.err = LoadErrors(l.warnings)
+	}
 	return l
 }
 
-//Save saves values to all non-default locales resources
+//SetStrict sets whether problems found by Load in resource files (e.g. strings defined twice),
+//by Save (strings without any value) and by csv import (unknown columns) are errors instead of warnings
+func (l *Localizer) SetStrict(strict bool) *Localizer {
+	l.strict = strict
+	return l
+}
+
+//Warnings returns problems found by the last Load (e.g. *DuplicateError)
+func (l *Localizer) Warnings() []error {
+	return l.warnings
+}
+
+//Save saves values to all non-default locales resources (and to default one if SetWriteDefault was called);
+//missing values are taken from fallback locales (see Resolve), strings that are not translated even there
+//are written according to untranslated policy (see SetUntranslatedPolicy); strings without value for locale and with
+//empty default value are not written and are reported by SaveWarnings (Save fails on them in strict mode
+//without writing anything); files which content would not change are left untouched (see ChangedFiles)
 func (l *Localizer) Save() error {
 	if l.err != nil {
 		return l.err
 	}
-	for _, loc := range l.Locales {
+	if _, err := l.writeFS(); err != nil {
+		// e.g. resources of archive
+		return err
+	}
+	if l.pseudoLocale != "" {
+		if err := l.GeneratePseudo(l.pseudoLocale, l.pseudoOptions...); err != nil {
+			return err
+		}
+	}
+	l.saveWarnings = nil
+	l.changedFiles = nil
+	files := make([]*xStrings, len(l.Locales))
+	for i, loc := range l.Locales {
 		if loc != defLocale {
-			res := &xStrings{Strings: []xString{}}
-			for n, s := range l.strings {
-				if s.Translatable {
-					v, ok := s.Values[loc]
-					if !ok {
-						v = s.Values[defLocale]
-					}
-					str := xString{Name: n, Value: v}
-					res.Strings = append(res.Strings, str)
-				}
+			files[i] = l.localeResources(loc)
+		}
+	}
+	if l.strict && len(l.saveWarnings) > 0 {
+		return LoadErrors(l.saveWarnings)
+	}
+	if l.writeDefault || len(l.renames) > 0 {
+		if err := l.saveDefault(); err != nil {
+			return err
+		}
+		l.renames = nil
+	}
+	for i, loc := range l.Locales {
+		if loc != defLocale {
+			err := l.makeLocaleDir(loc)
+			if err != nil {
+				return err
 			}
-			fileName := l.getFileNameForLocale(loc, true)
-			err := l.writeResources(fileName, res)
+			err = l.writeResources(l.getFileNameForLocale(loc), files[i])
 			if err != nil {
 				return err
 			}
 		}
 	}
+	if l.state != nil {
+		translated := l.recordTranslations()
+		if l.recordCopies(files) || translated {
+			return l.saveState()
+		}
+	}
 	return nil
 }
 
-//Export exports data to csv file
+// localeResources returns content of resources file of locale (with notes as comments);
+// translatable strings without value are added to save warnings
+func (l *Localizer) localeResources(loc string) *xStrings {
+	res := &xStrings{Attrs: l.rootAttrs[loc], Strings: []xString{}}
+	for _, n := range l.sortedNames() {
+		s := l.strings[n]
+		if l.isExcluded(n) {
+			// excluded strings are kept as they are
+			if v, ok := s.Values[loc]; ok {
+				res.Strings = append(res.Strings, xString{Name: n, Value: escapeAmpersands(v), Attrs: s.attrs[loc], Comment: s.Notes[loc]})
+			}
+		} else if s.IsReference() {
+			// references are resolved at build time: only locale's own references are kept
+			if v, ok := s.Values[loc]; ok {
+				if IsReference(v) {
+					res.Strings = append(res.Strings, xString{Name: n, Value: v, Attrs: s.attrs[loc], Comment: s.Notes[loc]})
+				} else {
+					l.logf("%s: value for '%s' is dropped: default value is reference", n, loc)
+				}
+			}
+		} else if !s.Translatable {
+			// values of non-translatable strings are dropped (see NonTranslatableValues)
+			if v, ok := s.Values[loc]; ok && l.keepNonTranslatable {
+				res.Strings = append(res.Strings, xString{Name: n, Value: escapeAmpersands(v), Attrs: s.attrs[loc], Comment: s.Notes[loc]})
+			}
+		} else {
+			v, ok := l.translation(s, loc)
+			_, _, item := splitItemName(n)
+			if !ok && item && s.Values[defLocale] == "" {
+				// plurals quantity of other languages (e.g. few)
+				continue
+			}
+			// elements with items are written whole
+			whole := !ok && item && l.hasTranslatedItems(s, loc)
+			if !ok && l.untranslated == UntranslatedOmit && !l.backfill && !whole {
+				continue
+			}
+			if !ok && l.untranslated == UntranslatedEmpty && !l.backfill && !whole {
+				res.Strings = append(res.Strings, xString{Name: n, Attrs: s.attrs[loc], Comment: s.Notes[loc]})
+				continue
+			}
+			if !ok {
+				v = s.Values[defLocale]
+			}
+			if v == "" {
+				// empty element would hide the gap (and is rejected by aapt sometimes)
+				l.saveWarnings = append(l.saveWarnings, &EmptyValueError{FileName: l.getFileNameForLocale(loc), Name: n, Locale: loc})
+				continue
+			}
+			v = l.transformed(n, loc, v)
+			res.Strings = append(res.Strings, xString{Name: n, Value: escapeAmpersands(v), Attrs: s.attrs[loc],
+				Comment: l.localeNote(s, loc, ok), copied: s.Values[loc] == ""})
+		}
+	}
+	return res
+}
+
+//NonTranslatableValues returns *NonTranslatableError for every value in locale files of string that is not
+//translatable in default resources (Load adds them to warnings); Save drops such values unless SetKeepNonTranslatable is called
+func (l *Localizer) NonTranslatableValues() []error {
+	if l.err != nil {
+		return []error{l.err}
+	}
+	var errs []error
+	for _, n := range l.sortedNames() {
+		s := l.strings[n]
+		if s.Translatable || l.isExcluded(n) {
+			continue
+		}
+		for _, loc := range l.Locales[1:] {
+			if _, ok := s.Values[loc]; ok {
+				errs = append(errs, &NonTranslatableError{FileName: l.getFileNameForLocale(loc), Name: n, Locale: loc})
+			}
+		}
+	}
+	return errs
+}
+
+//SetKeepNonTranslatable sets whether Save keeps values of non-translatable strings found in locale files
+//instead of dropping them
+func (l *Localizer) SetKeepNonTranslatable(keep bool) *Localizer {
+	l.keepNonTranslatable = keep
+	return l
+}
+
+//ChangedFiles returns paths of resource files written by the last Save (see ResourceFiles): files which
+//content would stay the same are not written (nor backed up)
+func (l *Localizer) ChangedFiles() []string {
+	return l.changedFiles
+}
+
+//SaveWarnings returns problems found by the last Save (*EmptyValueError for strings that were not written)
+func (l *Localizer) SaveWarnings() []error {
+	return l.saveWarnings
+}
+
+//Export exports data to csv file (compressed with gzip if name ends with .gz)
 func (l *Localizer) Export(fileName string) error {
 	if l.err != nil {
 		return l.err
 	}
-	of, err := os.Create(fileName)
-	if err != nil {
-		return err
-	}
-	defer of.Close()
-	return l.ExportW(of)
+	return l.exportFile(fileName, l.ExportW)
 }
 
 //ExportW writes data in csv format to given writer
@@ -145,38 +511,139 @@ func (l *Localizer) ExportW(w io.Writer) (err error) {
 	if l.err != nil {
 		return l.err
 	}
+	return l.writeCSV(w, l.delimiter(), l.Locales[1:], false)
+}
+
+//ExportTemplate exports csv file with default values and empty columns for given locales
+//(all the engine's locales if none given) to be filled by translators from scratch
+func (l *Localizer) ExportTemplate(fileName string, locales ...string) error {
+	if l.err != nil {
+		return l.err
+	}
+	return l.exportFile(fileName, func(w io.Writer) error {
+		return l.ExportTemplateW(w, locales...)
+	})
+}
+
+//ExportTemplateW writes template (see ExportTemplate) in csv format to given writer
+func (l *Localizer) ExportTemplateW(w io.Writer, locales ...string) error {
+	if l.err != nil {
+		return l.err
+	}
+	if len(locales) == 0 {
+		locales = l.Locales[1:]
+	}
+	return l.writeCSV(w, l.delimiter(), locales, true)
+}
+
+func (l *Localizer) writeCSV(w io.Writer, comma rune, locales []string, blank bool) error {
+	return l.writeCSVFiltered(w, comma, locales, blank, nil)
+}
+
+// writeCSVFiltered writes translatable strings for which filter (if given) returns true
+// (and references with context column if SetExportReferences was called and non-translatable strings
+// with translatable column if SetIncludeNonTranslatable was called);
+// notes of locales go to the last column if there are any; only mapped columns are written if there is column mapping;
+// rows go in order set by SetRowOrder
+func (l *Localizer) writeCSVFiltered(w io.Writer, comma rune, locales []string, blank bool, filter func(s *String) bool) (err error) {
 	cw := csv.NewWriter(w)
-	row := make([]string, len(l.Locales)+1)
-	row[0] = nameColumn
-	for i, l := range l.Locales {
-		row[i+1] = l
+	cw.Comma = comma
+	header := append([]string{nameColumn, defLocale}, locales...)
+	if l.includeNonTranslatable {
+		header = append(header, translatableColumn)
+	}
+	if l.exportReferences {
+		header = append(header, contextColumn)
+	}
+	if l.exportArguments {
+		header = append(header, argumentsColumn)
+	}
+	var statuses []string
+	if !blank {
+		statuses = l.statusLocales(locales)
+	}
+	for _, loc := range statuses {
+		header = append(header, statusColumnPrefix+loc)
+	}
+	if l.delta != nil {
+		header = append(header, deltaColumn)
+	}
+	notes := !blank && l.hasNotes(locales)
+	if notes {
+		header = append(header, noteColumn)
 	}
-	err = cw.Write(row)
+	columns := len(header)
+	row := make([]string, columns)
+	var cols []int
+	if l.mapping != nil {
+		cols, header = l.mapping.exportColumns(header)
+	}
+	err = cw.Write(header)
 	if err != nil {
 		return
 	}
-	for k, s := range l.strings {
-		if s.Translatable {
+	for _, k := range l.rowNames() {
+		s := l.strings[k]
+		ref := l.exportReferences && s.Translatable && !l.isExcluded(k) && l.isSelected(k) && s.IsReference()
+		nonTr := l.includeNonTranslatable && !s.Translatable && !l.isExcluded(k) && l.isSelected(k)
+		if (l.isTranslatable(s) || ref || nonTr) && (filter == nil || filter(s)) {
 			row[0] = k
-			for i, l := range l.Locales {
-				row[i+1], _ = s.Values[l]
+			row[1] = s.Values[defLocale]
+			for i, loc := range locales {
+				row[i+2] = ""
+				if !blank && !ref {
+					row[i+2] = s.Values[loc]
+				}
+			}
+			if l.transformExports && s.Translatable && !ref {
+				for i, loc := range append([]string{defLocale}, locales...) {
+					if row[i+1] != "" {
+						row[i+1] = l.transformed(k, loc, row[i+1])
+					}
+				}
+			}
+			for i := 1; i < len(locales)+2; i++ {
+				// translators edit text, not xml
+				row[i] = editableValue(row[i])
+			}
+			col := len(locales) + 2
+			if l.includeNonTranslatable {
+				row[col] = strconv.FormatBool(s.Translatable)
+				col++
+			}
+			if l.exportReferences {
+				row[col], _ = l.resolveReference(s, defLocale)
+				col++
+			}
+			if l.exportArguments {
+				row[col] = arguments(s)
+				col++
 			}
-			err = cw.Write(row)
+			for i, loc := range statuses {
+				row[col+i] = s.Status[loc]
+			}
+			if l.delta != nil {
+				row[col+len(statuses)] = l.delta[k]
+			}
+			if notes {
+				row[columns-1] = s.note(locales)
+			}
+			err = cw.Write(pick(row, cols))
 			if err != nil {
 				return
 			}
 		}
 	}
 	cw.Flush()
-	return nil
+	return cw.Error()
 }
 
-//Import imports data from csv file
+//Import imports data from csv file (compressed with gzip if name ends with .gz)
 func (l *Localizer) Import(fileName string) error {
 	if l.err != nil {
 		return l.err
 	}
-	f, err := os.Open(fileName)
+	f, err := l.openImportFile(fileName)
 	if err != nil {
 		return err
 	}
@@ -189,42 +656,199 @@ func (l *Localizer) ImportR(r io.Reader) (err error) {
 	if l.err != nil {
 		return l.err
 	}
+	return l.readCSV(r, l.delimiter(), l.importLocales)
+}
+
+//ImportLocales imports values in csv format from reader taking only columns of given locales
+//(all of them must be in csv); other columns are ignored
+func (l *Localizer) ImportLocales(r io.Reader, locales ...string) error {
+	if l.err != nil {
+		return l.err
+	}
+	return l.readCSV(r, l.delimiter(), locales)
+}
+
+//SetImportLocales sets locales values are taken for by imports (all the locales of file if none given)
+func (l *Localizer) SetImportLocales(locales ...string) *Localizer {
+	l.importLocales = locales
+	return l
+}
+
+// readCSV reads and checks the whole csv and applies values only if there are no problems in it;
+// columns are found by header in any order (default values column is optional),
+// only columns of given locales are applied (all if none given)
+func (l *Localizer) readCSV(r io.Reader, comma rune, only []string) (err error) {
 	cr := csv.NewReader(r)
-	cr.ReuseRecord = true
+	cr.Comma = comma
+	cr.FieldsPerRecord = -1
 	row, err := cr.Read()
 	if err != nil {
 		return err
 	}
-	if row[0] != nameColumn {
-		return fmt.Errorf("invalid csv format: first column name should be '%s', not '%s'", nameColumn, row[0])
+	if l.mapping != nil {
+		row = l.mapping.importHeader(row)
+	}
+	nameCol, defCol, noteCol, trCol := -1, -1, -1, -1
+	statusCols := map[string]int{}
+	// locales by column index; columns that are not locales (e.g. vendor's bookkeeping) are ignored
+	// (they are errors in strict mode)
+	locales := map[int]string{}
+	found := map[string]bool{}
+	for i, h := range row {
+		if h == "" && l.mapping != nil {
+			// column is not mapped
+			continue
+		}
+		if (h == nameColumn && nameCol >= 0) || (h == defLocale && defCol >= 0) {
+			return fmt.Errorf("%w: column '%s' is given twice", ErrInvalidHeader, h)
+		}
+		if h == nameColumn {
+			nameCol = i
+			continue
+		}
+		if h == defLocale {
+			defCol = i
+			continue
+		}
+		if h == contextColumn || h == argumentsColumn || h == deltaColumn {
+			// resolved values of references and placeholders (and kinds of delta rows) are for translators only
+			continue
+		}
+		if h == noteColumn {
+			noteCol = i
+			continue
+		}
+		if h == translatableColumn {
+			trCol = i
+			continue
+		}
+		if loc, ok := l.statusColumnLocale(h); ok {
+			statusCols[loc] = i
+			continue
+		}
+		loc, ok := l.columnLocale(h)
+		if !ok {
+			if l.strict {
+				return fmt.Errorf("%w: column '%s' is neither '%s', '%s' nor locale", ErrInvalidHeader, h, nameColumn, defLocale)
+			}
+			l.logf("column '%s' is ignored: not a locale", h)
+			continue
+		}
+		if found[loc] {
+			return fmt.Errorf("%w: column of locale '%s' is given twice", ErrInvalidHeader, loc)
+		}
+		found[loc] = true
+		if len(only) > 0 && !contains(only, loc) {
+			l.logf("column '%s' is ignored: locale is not imported", row[i])
+			continue
+		}
+		locales[i] = loc
 	}
-	if row[1] != defLocale {
-		return fmt.Errorf("invalid csv format: second column name should be '%s', not '%s'", defLocale, row[1])
+	if nameCol < 0 {
+		return fmt.Errorf("%w: column '%s' is not found", ErrInvalidHeader, nameColumn)
 	}
-	locales := make([]string, len(row)-2)
-	for i := 2; i < len(row); i++ {
-		l.addLocale(row[i])
-		locales[i-2] = row[i]
+	for _, loc := range only {
+		if !found[loc] {
+			return &MissingLocaleError{Locale: loc}
+		}
 	}
 
+	header := len(row)
+	summary := &ImportSummary{}
+	l.lastImport = summary
+	type update struct {
+		s   *String
+		row []string
+	}
+	updates := []update{}
 	for {
 		row, err = cr.Read()
 		if err != nil {
 			if err == io.EOF {
 				break
-			} else {
-				return err
 			}
+			return err
+		}
+		line, _ := cr.FieldPos(0)
+		name := ""
+		if nameCol < len(row) {
+			name = row[nameCol]
+		}
+		if len(row) != header {
+			summary.Problems = append(summary.Problems, &RowError{Line: line, Name: name,
+				Err: fmt.Errorf("%d columns instead of %d", len(row), header)})
+			continue
+		}
+		if l.isExcluded(name) || strings.HasPrefix(name, contextMark) {
+			// excluded string or read-only context row
+			summary.Skipped++
+			continue
 		}
-		s, ok := l.strings[row[0]]
+		if trCol >= 0 && strings.TrimSpace(row[trCol]) == "false" {
+			l.logf("%s is skipped: row is marked not translatable", name)
+			summary.Skipped++
+			continue
+		}
+		s, ok := l.strings[name]
 		if !ok {
-			return fmt.Errorf("value with name '%s' from csv is not found in resources file", row[0])
+			// plurals quantity that is not in resources yet is added on import
+			var err error
+			if s, err = l.importedItem(name); s == nil && err == nil {
+				err = &UnknownKeyError{Name: name, Source: "csv"}
+			}
+			if err != nil {
+				summary.Problems = append(summary.Problems, &RowError{Line: line, Name: name, Err: err})
+				continue
+			}
+		}
+		if s.IsReference() {
+			l.logf("%s is skipped: default value is reference", name)
+			summary.Skipped++
+			continue
+		}
+		if !s.Translatable {
+			l.logf("%s is skipped: string is not translatable", name)
+			summary.Skipped++
+			continue
+		}
+		updates = append(updates, update{s, row})
+	}
+	if len(summary.Problems) > 0 {
+		return &ImportError{Summary: summary}
+	}
+	// locales of header are added only if the import is applied
+	for i := 0; i < header; i++ {
+		if loc, ok := locales[i]; ok {
+			l.addLocale(loc)
+		}
+	}
+	for _, u := range updates {
+		if l.writeDefault && defCol >= 0 && !(l.skipEmptyCells && strings.TrimSpace(u.row[defCol]) == "") {
+			u.s.Values[defLocale] = normalizeValue(l.importedNewlines(u.row[defCol]), u.s.Values[defLocale])
 		}
 		for i, loc := range locales {
-			s.Values[loc] = row[i+2]
+			if l.skipEmptyCells && strings.TrimSpace(u.row[i]) == "" || l.keepsReference(u.s, loc, u.row[i]) {
+				continue
+			}
+			u.s.Values[loc] = normalizeValue(l.importedNewlines(u.row[i]), u.s.Values[loc], u.s.Values[defLocale])
+			if noteCol >= 0 {
+				u.s.SetNote(loc, strings.TrimSpace(u.row[noteCol]))
+			}
+			if col, ok := statusCols[loc]; ok {
+				u.s.SetStatus(loc, strings.TrimSpace(u.row[col]))
+			}
+		}
+		if _, ok := l.strings[u.s.Name]; !ok && u.s.hasValues() {
+			l.strings[u.s.Name] = u.s
 		}
 	}
-	return
+	summary.Applied = len(updates)
+	return nil
+}
+
+//LastImport returns summary of the last csv import (nil if there was no one)
+func (l *Localizer) LastImport() *ImportSummary {
+	return l.lastImport
 }
 
 //Strings returns imported strings slice
@@ -237,86 +861,348 @@ func (l *Localizer) Err() error {
 	return l.err
 }
 
-func (l *Localizer) addLocales(ls []string) {
-	for _, loc := range ls {
-		l.addLocale(loc)
+//SetLogger sets logger for verbose messages (e.g. about ignored csv columns)
+func (l *Localizer) SetLogger(logger *log.Logger) *Localizer {
+	l.logger = logger
+	return l
+}
+
+func (l *Localizer) logf(format string, args ...interface{}) {
+	if l.logger != nil {
+		l.logger.Printf(format, args...)
 	}
 }
 
+//SetExcludePatterns sets name patterns (path.Match globs, e.g. "debug_*") of strings that are
+//not exported, are ignored on import and are left untouched in locale files on save
+func (l *Localizer) SetExcludePatterns(patterns []string) *Localizer {
+	l.exclude = patterns
+	return l
+}
+
+func (l *Localizer) isExcluded(name string) bool {
+	return matchesAny(name, l.exclude) || matchesAny(name, l.ignorePatterns) || l.excludedNames[name]
+}
+
+//WithKeyPrefix limits export, statistics and validation to strings which names start with prefix (e.g. "checkout_")
+func (l *Localizer) WithKeyPrefix(prefix string) *Localizer {
+	l.keyPrefix = prefix
+	return l
+}
+
+//WithKeyPattern limits export, statistics and validation to strings which names match re (nil means all)
+func (l *Localizer) WithKeyPattern(re *regexp.Regexp) *Localizer {
+	l.keyPattern = re
+	return l
+}
+
+// isSelected returns true if name passes key filters (see WithKeyPrefix and WithKeyPattern)
+func (l *Localizer) isSelected(name string) bool {
+	return strings.HasPrefix(name, l.keyPrefix) && (l.keyPattern == nil || l.keyPattern.MatchString(name))
+}
+
+// isTranslatable returns true if string should be translated (is translatable, is not excluded,
+// is selected by key filters and is not reference)
+func (l *Localizer) isTranslatable(s *String) bool {
+	return s.Translatable && !l.isExcluded(s.Name) && l.isSelected(s.Name) && !s.IsReference()
+}
+
+func (l *Localizer) sortedNames() []string {
+	names := make([]string, 0, len(l.strings))
+	for n := range l.strings {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// addLocale adds locale found in imported data or created by engine's operations
 func (l *Localizer) addLocale(loc string) {
+	l.addLocaleFrom(loc, LocaleAdded)
+}
+
+func (l *Localizer) addLocaleFrom(loc string, source LocaleSource) {
+	if valuesDir+"-"+loc == l.defaultDir {
+		// resources of locale are default ones
+		return
+	}
 	for _, lc := range l.Locales {
 		if lc == loc {
 			return
 		}
 	}
 	l.Locales = append(l.Locales, loc)
+	l.sortLocales()
+	if l.localeSources == nil {
+		l.localeSources = map[string]LocaleSource{}
+	}
+	l.localeSources[loc] = source
 }
 
-func (l *Localizer) getFileNameForLocale(loc string, checkDir bool) string {
+func (l *Localizer) getFileNameForLocale(loc string) string {
 	if loc == defLocale {
-		return filepath.Join(l.ResourcesDir, valuesDir, stringsFile)
+		return filepath.Join(l.ResourcesDir, l.defaultDir, l.stringsFileName())
 	}
-	dir := filepath.Join(l.ResourcesDir, valuesDir+"-"+loc)
-	if checkDir {
-		_, e := os.Stat(dir)
-		if e != nil {
-			os.Mkdir(dir, os.ModePerm)
+	return filepath.Join(l.ResourcesDir, valuesDir+"-"+loc, l.stringsFileName())
+}
+
+// makeLocaleDir creates values dir for locale if it does not exist
+func (l *Localizer) makeLocaleDir(loc string) error {
+	dir := filepath.Dir(l.getFileNameForLocale(loc))
+	err := l.mkdirAll(dir)
+	if err != nil {
+		var pe *fs.PathError
+		if errors.As(err, &pe) {
+			err = pe.Err
 		}
+		return fmt.Errorf("could not create locale directory %s: %w", filepath.Base(dir), err)
+	}
+	return nil
+}
+
+type resourcesFile struct {
+	res *xStrings
+	err error
+}
+
+// readAllResources reads resource files of all the locales in parallel (at most loadWorkers at once)
+// and returns them in order of l.Locales
+func (l *Localizer) readAllResources() []resourcesFile {
+	files := make([]resourcesFile, len(l.Locales))
+	sem := make(chan struct{}, loadWorkers)
+	var wg sync.WaitGroup
+	for i, loc := range l.Locales {
+		wg.Add(1)
+		go func(i int, fileName string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			files[i].res, files[i].err = l.readResources(fileName)
+		}(i, l.getFileNameForLocale(loc))
 	}
-	return filepath.Join(dir, stringsFile)
+	wg.Wait()
+	return files
 }
 
 func (l *Localizer) readResources(fileName string) (resources *xStrings, err error) {
-	f, err := os.Open(fileName)
+	f, err := l.open(fileName)
 	if err != nil {
 		return
 	}
 	defer f.Close()
-	byteValue, _ := ioutil.ReadAll(f)
-	resources = &xStrings{}
-	err = xml.Unmarshal(byteValue, resources)
-	return
+	return parseResources(f)
 }
 
-func (l *Localizer) writeResources(fileName string, resources *xStrings) (err error) {
-	_, e := os.Stat(fileName)
-	if e == nil {
-		os.Rename(fileName, fileName+".bak")
+// parseResources decodes string elements of resources file remembering comments preceding them;
+// items of plurals and string-array elements become strings named name:quantity and name:index
+func parseResources(r io.Reader) (*xStrings, error) {
+	resources := &xStrings{}
+	// files edited on Windows may start with byte order mark and declare other encodings (e.g. windows-1251)
+	d := xml.NewDecoder(skipBOM(r))
+	d.CharsetReader = charsetReader
+	depth := 0
+	comment := ""
+	var prefixes map[string]string
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			if resources.XMLName.Local == "" {
+				return nil, fmt.Errorf("expected element type <resources> but have none")
+			}
+			return resources, nil
+		}
+		if err != nil {
+			return nil, syntaxError(d, err)
+		}
+		switch t := t.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				if t.Name.Local != "resources" {
+					return nil, fmt.Errorf("expected element type <resources> but have <%s>", t.Name.Local)
+				}
+				resources.XMLName = t.Name
+				prefixes = namespacePrefixes(t.Attr)
+				resources.Attrs = prefixedAttrs(t.Attr, prefixes)
+				depth++
+			} else if t.Name.Local == "string" {
+				s := xString{}
+				if err = d.DecodeElement(&s, &t); err != nil {
+					return nil, syntaxError(d, err)
+				}
+				s.Comment = comment
+				s.Attrs = prefixedAttrs(s.Attrs, prefixes)
+				resources.Strings = append(resources.Strings, s)
+				comment = ""
+			} else if t.Name.Local == pluralsElement || t.Name.Local == arrayElement {
+				x := &xItems{}
+				if err = d.DecodeElement(x, &t); err != nil {
+					return nil, syntaxError(d, err)
+				}
+				x.Attrs = prefixedAttrs(x.Attrs, prefixes)
+				resources.Strings = append(resources.Strings, flattenItems(x, comment)...)
+				comment = ""
+			} else {
+				if err = d.Skip(); err != nil {
+					return nil, syntaxError(d, err)
+				}
+				comment = ""
+			}
+		case xml.EndElement:
+			depth--
+		case xml.Comment:
+			if depth == 1 {
+				comment = strings.TrimSpace(string(t))
+			}
+		}
 	}
-	f, err := os.Create(fileName)
+}
+
+// syntaxError converts xml syntax error to *ParseError with position of decoder
+func syntaxError(d *xml.Decoder, err error) error {
+	var se *xml.SyntaxError
+	if !errors.As(err, &se) {
+		return err
+	}
+	line, col := d.InputPos()
+	return &ParseError{Line: line, Column: col, Msg: se.Msg}
+}
+
+// writeResources writes resources file in the same layout as Android Studio does
+// (declaration first and line break at the end); comments of strings are written before them
+func (l *Localizer) writeResources(fileName string, resources *xStrings) error {
+	content, err := l.resourcesContent(fileName, resources)
 	if err != nil {
-		return
+		return err
 	}
-	defer f.Close()
-	var bytes []byte
-	bytes, err = xml.MarshalIndent(resources, "", xmlIndent)
-	if err == nil {
-		f.Write(bytes)
+	return l.writeContent(fileName, content)
+}
+
+// resourcesContent returns content of resources file written by writeResources
+func (l *Localizer) resourcesContent(fileName string, resources *xStrings) ([]byte, error) {
+	indent := l.indentFor(fileName)
+	out := bytes.Buffer{}
+	out.WriteString(xmlDeclaration)
+	writeStartTag(&out, "resources", resources.Attrs)
+	out.WriteString("\n")
+	enc := xml.NewEncoder(&out)
+	start := xml.StartElement{Name: xml.Name{Local: "string"}}
+	for i := 0; i < len(resources.Strings); i++ {
+		s := resources.Strings[i]
+		if _, _, ok := splitItemName(s.Name); ok {
+			i += writeItems(&out, indent, resources.Strings[i:]) - 1
+			continue
+		}
+		if s.Comment != "" {
+			fmt.Fprintf(&out, "%s%s\n", indent, xmlComment(s.Comment))
+		}
+		out.WriteString(indent)
+		if err := enc.EncodeElement(s, start); err != nil {
+			return nil, err
+		}
+		out.WriteString("\n")
+	}
+	out.WriteString("</resources>\n")
+	return out.Bytes(), nil
+}
+
+// xmlComment returns xml comment with text; -- (not allowed in comments) is separated with spaces
+// as many times as needed (--- becomes - - -), text ending with - is separated from --> with space
+func xmlComment(text string) string {
+	for strings.Contains(text, "--") {
+		text = strings.Replace(text, "--", "- -", -1)
 	}
-	return
+	return "<!-- " + text + " -->"
+}
+
+// writeContent replaces resource file with content backing it up; file with the same content is not touched
+func (l *Localizer) writeContent(fileName string, content []byte) error {
+	if current, err := l.readFile(fileName); err == nil && bytes.Equal(current, content) {
+		return nil
+	}
+	if err := l.backup(fileName); err != nil {
+		return err
+	}
+	if err := l.writeResourceFile(fileName, content); err != nil {
+		return err
+	}
+	l.changedFiles = append(l.changedFiles, l.absPath(fileName))
+	return nil
 }
 
 func (l *Localizer) guessLocales() {
 	templ := valuesDir + "-"
-	files, err := ioutil.ReadDir(l.ResourcesDir)
+	files, err := l.readDir(l.ResourcesDir)
 	if err == nil {
 		for _, f := range files {
-			if f.IsDir() && strings.Index(f.Name(), templ) == 0 {
-				l.Locales = append(l.Locales, f.Name()[len(templ):])
+			if !f.IsDir() || strings.Index(f.Name(), templ) != 0 || isBackup(f.Name()) {
+				continue
+			}
+			// other qualifiers (values-night, values-v21, values-sw600dp) are not locales
+			loc := f.Name()[len(templ):]
+			if norm, err := NormalizeLocale(loc); err == nil && norm == loc {
+				l.addLocaleFrom(loc, LocaleGuessed)
 			}
 		}
 	}
 }
 
-func checkPathIsResourcesDir(p string) error {
-	rs, err := os.Stat(p)
-	if err == nil {
-		if !rs.IsDir() {
-			err = fmt.Errorf("%s is not a dir", p)
-		} else {
-			strFile := filepath.Join(p, valuesDir, stringsFile)
-			rs, err = os.Stat(strFile)
+func (l *Localizer) stringsFileName() string {
+	if l.StringsFileName == "" {
+		return stringsFile
+	}
+	return l.StringsFileName
+}
+
+// checkPathIsResourcesDir checks that p contains strings file in values dir or, if there is no bare values,
+// in some of values-* dirs; returns name of dir of default locale
+func (l *Localizer) checkPathIsResourcesDir(p string) (string, error) {
+	rs, err := l.stat(p)
+	if err != nil {
+		return "", err
+	}
+	if !rs.IsDir() {
+		return "", fmt.Errorf("%s is not a dir", p)
+	}
+	strFile := filepath.Join(p, valuesDir, l.stringsFileName())
+	if _, err = l.stat(strFile); err == nil {
+		return valuesDir, nil
+	}
+	if dir := l.qualifiedDefaultDir(p); dir != "" {
+		return dir, nil
+	}
+	return "", err
+}
+
+// qualifiedDefaultDir returns values-* dir of p with strings file to be used as default one
+// (values-en if there is such one, the first found otherwise) or empty string if there are no such dirs
+func (l *Localizer) qualifiedDefaultDir(p string) string {
+	files, err := l.readDir(p)
+	if err != nil {
+		return ""
+	}
+	found := ""
+	for _, f := range files {
+		if !f.IsDir() || !strings.HasPrefix(f.Name(), valuesDir+"-") || isBackup(f.Name()) {
+			continue
+		}
+		if _, err = l.stat(filepath.Join(p, f.Name(), l.stringsFileName())); err != nil {
+			continue
+		}
+		if f.Name() == valuesDir+"-en" {
+			return f.Name()
+		}
+		if found == "" {
+			found = f.Name()
 		}
 	}
-	return err
+	return found
 }
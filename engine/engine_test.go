@@ -0,0 +1,69 @@
+package engine
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testDefault = `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <string name="app_name" translatable="false">Test</string>
+    <string name="hello">Hello</string>
+    <string name="bye">Bye</string>
+</resources>
+`
+
+// writeProject writes files (paths are relative to resources dir) to temporary dir and returns its path
+func writeProject(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// loadProject returns engine with loaded project of files
+func loadProject(t *testing.T, files map[string]string, locales ...string) *Localizer {
+	t.Helper()
+	l := New(writeProject(t, files), locales...).Load()
+	if err := l.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+// readProjectFile returns content of file of project (path is relative to resources dir)
+func readProjectFile(t *testing.T, l *Localizer, name string) string {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join(l.ResourcesDir, filepath.FromSlash(name)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func TestRejectedImportAddsNoLocales(t *testing.T) {
+	l := loadProject(t, map[string]string{"values/strings.xml": testDefault})
+	err := l.ImportR(strings.NewReader("id,def,fr\nhello,Hello,Bonjour\nunknown,Unknown,Inconnu\n"))
+	var ie *ImportError
+	if !errors.As(err, &ie) {
+		t.Fatalf("error is %v", err)
+	}
+	if !reflect.DeepEqual(l.Locales, []string{defLocale}) {
+		t.Errorf("locales are %v", l.Locales)
+	}
+	if _, ok := l.Strings()["hello"].Values["fr"]; ok {
+		t.Error("value of rejected import is applied")
+	}
+}
@@ -0,0 +1,266 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+//ErrInvalidHeader is wrapped by errors of invalid csv header (e.g. without id column)
+var ErrInvalidHeader = errors.New("invalid csv format")
+
+//ErrLocaleExists is wrapped by error of CreateLocale if locale resources file already exists
+var ErrLocaleExists = errors.New("locale already exists")
+
+//MissingLocaleError is returned by import if locale that should be imported has no column in csv header;
+//it matches ErrInvalidHeader
+type MissingLocaleError struct {
+	Locale string
+}
+
+func (e *MissingLocaleError) Error() string {
+	return fmt.Sprintf("locale '%s' is not found in csv header", e.Locale)
+}
+
+func (e *MissingLocaleError) Is(target error) bool {
+	return target == ErrInvalidHeader
+}
+
+//UnknownKeyError describes imported value which name is not found in resources file
+type UnknownKeyError struct {
+	Name string
+	//Source is format of imported file (csv, json, arb)
+	Source string
+}
+
+func (e *UnknownKeyError) Error() string {
+	return fmt.Sprintf("value with name '%s' from %s is not found in resources file", e.Name, e.Source)
+}
+
+//FileError is error of processing file
+type FileError struct {
+	FileName string
+	Err      error
+}
+
+func (e *FileError) Error() string {
+	return fmt.Sprintf("%s: %v", e.FileName, e.Err)
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+//ParseError describes syntax error of resource file (it is wrapped by *FileError)
+type ParseError struct {
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+//LoadErrors contains errors of all the resource files that could not be loaded
+//(or all the problems found by Load or Save in strict mode)
+type LoadErrors []error
+
+func (e LoadErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+//Is reports whether any of errors matches target (see errors.Is)
+func (e LoadErrors) Is(target error) bool {
+	return anyIs(e, target)
+}
+
+//As finds the first of errors that matches target (see errors.As)
+func (e LoadErrors) As(target interface{}) bool {
+	return anyAs(e, target)
+}
+
+//DuplicateError describes string defined more than once in resource file (the last definition is used)
+type DuplicateError struct {
+	FileName string
+	Name     string
+	First    string
+	Second   string
+}
+
+func (e *DuplicateError) Error() string {
+	return fmt.Sprintf("%s: string '%s' is defined twice: '%s' and '%s'", e.FileName, e.Name, e.First, e.Second)
+}
+
+//NonTranslatableError describes value in locale file of string that is not translatable (translatable="false")
+//in default resources; such values are dropped on save unless SetKeepNonTranslatable is called
+type NonTranslatableError struct {
+	FileName string
+	Name     string
+	Locale   string
+}
+
+func (e *NonTranslatableError) Error() string {
+	return fmt.Sprintf("%s: string '%s' is not translatable but has value for %s", e.FileName, e.Name, e.Locale)
+}
+
+//EmptyValueError describes translatable string that has no value for locale and empty default value
+type EmptyValueError struct {
+	FileName string
+	Name     string
+	Locale   string
+}
+
+func (e *EmptyValueError) Error() string {
+	return fmt.Sprintf("%s: string '%s' is not written: no value for %s and default value is empty", e.FileName, e.Name, e.Locale)
+}
+
+//ImportSummary contains results of csv import
+type ImportSummary struct {
+	//Applied is count of rows values were taken from
+	Applied int
+	//Skipped is count of rows ignored because the strings are excluded, not translatable or references
+	Skipped int
+	//Problems contains *RowError for every invalid row
+	Problems []error
+}
+
+func (s *ImportSummary) String() string {
+	return fmt.Sprintf("%d rows applied, %d skipped, %d problems", s.Applied, s.Skipped, len(s.Problems))
+}
+
+//RowError is error of csv row
+type RowError struct {
+	Line int
+	Name string
+	Err  error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+//ImportError is returned if there were invalid rows in csv; no values are imported in this case
+type ImportError struct {
+	Summary *ImportSummary
+}
+
+func (e *ImportError) Error() string {
+	msgs := []string{fmt.Sprintf("import failed, nothing is imported: %d invalid rows", len(e.Summary.Problems))}
+	for _, p := range e.Summary.Problems {
+		msgs = append(msgs, p.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+//Is reports whether any of problems matches target (see errors.Is)
+func (e *ImportError) Is(target error) bool {
+	return anyIs(e.Summary.Problems, target)
+}
+
+//As finds the first of problems that matches target (e.g. **UnknownKeyError)
+func (e *ImportError) As(target interface{}) bool {
+	return anyAs(e.Summary.Problems, target)
+}
+
+//MergeConflict describes cell that has different values in merged csv files
+type MergeConflict struct {
+	Name   string
+	Column string
+	Files  []string
+	Values []string
+}
+
+func (c *MergeConflict) Error() string {
+	return fmt.Sprintf("%s, column %s: '%s' (%s) and '%s' (%s)", c.Name, c.Column, c.Values[0], c.Files[0], c.Values[1], c.Files[1])
+}
+
+//MergeError is returned by MergeCSV if merged files have conflicts; nothing is written in this case
+type MergeError struct {
+	Conflicts []*MergeConflict
+}
+
+func (e *MergeError) Error() string {
+	msgs := []string{fmt.Sprintf("merge failed, nothing is written: %d conflicts", len(e.Conflicts))}
+	for _, c := range e.Conflicts {
+		msgs = append(msgs, c.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+//FileImportResult contains result of import of file of dir
+type FileImportResult struct {
+	FileName string
+	//Summary is nil if file could not be read
+	Summary *ImportSummary
+	Err     error
+}
+
+func (r *FileImportResult) String() string {
+	if r.Err != nil {
+		return fmt.Sprintf("%s: failed: %v", r.FileName, r.Err)
+	}
+	return fmt.Sprintf("%s: %s", r.FileName, r.Summary)
+}
+
+//DirImportError is returned if some of files of dir could not be imported (others are imported)
+type DirImportError struct {
+	Results []*FileImportResult
+	Failed  int
+}
+
+func (e *DirImportError) Error() string {
+	msgs := []string{fmt.Sprintf("%d of %d files are not imported", e.Failed, len(e.Results))}
+	for _, r := range e.Results {
+		if r.Err != nil {
+			msgs = append(msgs, r.String())
+		}
+	}
+	return strings.Join(msgs, "\n")
+}
+
+//Is reports whether error of any of files matches target
+func (e *DirImportError) Is(target error) bool {
+	return anyIs(e.errs(), target)
+}
+
+//As finds the first error of files that matches target
+func (e *DirImportError) As(target interface{}) bool {
+	return anyAs(e.errs(), target)
+}
+
+func (e *DirImportError) errs() []error {
+	var errs []error
+	for _, r := range e.Results {
+		if r.Err != nil {
+			errs = append(errs, r.Err)
+		}
+	}
+	return errs
+}
+
+func anyIs(errs []error, target error) bool {
+	for _, err := range errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func anyAs(errs []error, target interface{}) bool {
+	for _, err := range errs {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
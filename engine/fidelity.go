@@ -0,0 +1,81 @@
+package engine
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+)
+
+var entityRegexp = regexp.MustCompile(`^&(?:[a-zA-Z][a-zA-Z0-9]*|#[0-9]+|#x[0-9a-fA-F]+);`)
+
+//CheckRoundTrip checks that writing default locale strings as they are held in engine (the way Save writes
+//locale files) gives xml that is semantically identical to the default resources file
+func (l *Localizer) CheckRoundTrip() error {
+	if l.err != nil {
+		return l.err
+	}
+	orig, err := l.readResources(l.getFileNameForLocale(defLocale))
+	if err != nil {
+		return err
+	}
+	res := &xStrings{Attrs: orig.Attrs, Strings: []xString{}}
+	for _, n := range l.sortedNames() {
+		s := l.strings[n]
+		if _, ok := s.Values[defLocale]; !ok {
+			continue
+		}
+		str := xString{Name: n, Value: escapeAmpersands(s.Values[defLocale]), Attrs: s.attrs[defLocale]}
+		if !s.Translatable {
+			str.Translatable = "false"
+		}
+		res.Strings = append(res.Strings, str)
+	}
+	content, err := l.resourcesContent(l.getFileNameForLocale(defLocale), res)
+	if err != nil {
+		return err
+	}
+	saved, err := parseResources(bytes.NewReader(content))
+	if err != nil {
+		return fmt.Errorf("generated xml can not be parsed: %v", err)
+	}
+	if fmt.Sprint(saved.Attrs) != fmt.Sprint(orig.Attrs) {
+		return fmt.Errorf("attributes of <resources> changed")
+	}
+	savedByName := map[string]xString{}
+	for _, s := range saved.Strings {
+		savedByName[s.Name] = s
+	}
+	for _, o := range orig.Strings {
+		s, ok := savedByName[o.Name]
+		if !ok {
+			return fmt.Errorf("%s: string is lost", o.Name)
+		}
+		if s.Value != o.Value {
+			return fmt.Errorf("%s: value '%s' became '%s'", o.Name, o.Value, s.Value)
+		}
+		if (s.Translatable == "false") != (o.Translatable == "false") {
+			return fmt.Errorf("%s: translatable attribute changed", o.Name)
+		}
+		if fmt.Sprint(s.Attrs) != fmt.Sprint(o.Attrs) {
+			return fmt.Errorf("%s: attributes changed", o.Name)
+		}
+	}
+	if len(savedByName) != len(orig.Strings) {
+		return fmt.Errorf("strings count changed: %d instead of %d", len(savedByName), len(orig.Strings))
+	}
+	return nil
+}
+
+// escapeAmpersands encodes ampersands that do not start an entity or character reference,
+// so values that are already escaped are not escaped twice
+func escapeAmpersands(v string) string {
+	res := []byte{}
+	for i := 0; i < len(v); i++ {
+		if v[i] == '&' && !entityRegexp.MatchString(v[i:]) {
+			res = append(res, "&amp;"...)
+		} else {
+			res = append(res, v[i])
+		}
+	}
+	return string(res)
+}
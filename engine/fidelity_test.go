@@ -0,0 +1,139 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+)
+
+const testEscapedDefault = `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <string name="amp">Tom &amp; Jerry</string>
+    <string name="less">a &lt; b &gt; c</string>
+    <string name="quotes">l\'app \"x\" &quot;y&quot;</string>
+    <string name="markup"><b>Hi</b> <xliff:g id="user">%1$s</xliff:g></string>
+    <string name="tag_text">&lt;b&gt; is bold</string>
+</resources>
+`
+
+func TestCheckRoundTrip(t *testing.T) {
+	l := loadProject(t, map[string]string{"values/strings.xml": testEscapedDefault})
+	if err := l.CheckRoundTrip(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSaveDoesNotEscapeTwice(t *testing.T) {
+	l := loadProject(t, map[string]string{"values/strings.xml": testEscapedDefault}, "de")
+	for n, s := range l.Strings() {
+		s.Values["de"] = s.Values[defLocale]
+		if n == "amp" {
+			s.Values["de"] = "Tom & Jerry"
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if err := l.Save(); err != nil {
+			t.Fatal(err)
+		}
+		if l = New(l.ResourcesDir).Load(); l.Err() != nil {
+			t.Fatal(l.Err())
+		}
+	}
+	de := readProjectFile(t, l, "values-de/strings.xml")
+	for _, want := range []string{"Tom &amp; Jerry<", "a &lt; b &gt; c", `l\'app \"x\" &quot;y&quot;`, "&lt;b&gt; is bold"} {
+		if !strings.Contains(de, want) {
+			t.Errorf("%q is not found in\n%s", want, de)
+		}
+	}
+}
+
+func TestExportEditableValues(t *testing.T) {
+	l := loadProject(t, map[string]string{"values/strings.xml": testEscapedDefault})
+	buf := bytes.Buffer{}
+	if err := l.ExportW(&buf); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := map[string]string{}
+	for _, row := range rows[1:] {
+		values[row[0]] = row[1]
+	}
+	want := map[string]string{
+		"amp":      "Tom & Jerry",
+		"less":     "a < b > c",
+		"quotes":   `l\'app \"x\" &quot;y&quot;`,
+		"markup":   `<b>Hi</b> <xliff:g id="user">%1$s</xliff:g>`,
+		"tag_text": "&lt;b> is bold",
+	}
+	for n, v := range want {
+		if values[n] != v {
+			t.Errorf("%s: exported %q instead of %q", n, values[n], v)
+		}
+	}
+	// unchanged export is imported without changes
+	before := map[string]string{}
+	for n, s := range l.Strings() {
+		before[n] = s.Values[defLocale]
+	}
+	exported := bytes.Buffer{}
+	l.ExportW(&exported)
+	if err = l.SetWriteDefault(true).ImportR(&exported); err != nil {
+		t.Fatal(err)
+	}
+	for n, s := range l.Strings() {
+		if s.Values[defLocale] != before[n] {
+			t.Errorf("%s: %q became %q", n, before[n], s.Values[defLocale])
+		}
+	}
+}
+
+func TestImportEscapesText(t *testing.T) {
+	l := loadProject(t, map[string]string{"values/strings.xml": testDefault}, "fr")
+	input := "id,def,fr\n" +
+		"hello,Hello,\"a < b & c > d, l'app \"\"x\"\" &amp; <b>gras</b>\"\n" +
+		"bye,Bye,\"\"\" l'espace \"\"\"\n"
+	if err := l.ImportR(strings.NewReader(input)); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"hello": `a &lt; b &amp; c &gt; d, l\'app \"x\" &amp; <b>gras</b>`,
+		"bye":   `" l'espace "`,
+	}
+	for n, v := range want {
+		if got := l.Strings()[n].Values["fr"]; got != v {
+			t.Errorf("%s: imported %q instead of %q", n, got, v)
+		}
+	}
+	if err := l.Save(); err != nil {
+		t.Fatal(err)
+	}
+	l = New(l.ResourcesDir).Load()
+	if err := l.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if text := PlainText(l.Strings()["hello"].Values["fr"]); text != `a < b & c > d, l'app "x" & gras` {
+		t.Errorf("saved value is shown as %q", text)
+	}
+}
+
+func TestImportedValue(t *testing.T) {
+	l := loadProject(t, map[string]string{"values/strings.xml": testEscapedDefault}, "fr")
+	s := l.Strings()["amp"]
+	tests := []struct {
+		value, want string
+	}{
+		{"Tom &amp; Jerry", "Tom &amp; Jerry"},
+		{"Tom & Jerry", "Tom &amp; Jerry"},
+		{"l'a < b\nc", `l\'a &lt; b\nc`},
+		{"<b>Tom</b> & Jerry ", "<b>Tom</b> &amp; Jerry"},
+	}
+	for _, tt := range tests {
+		if got := l.ImportedValue(s, "fr", tt.value); got != tt.want {
+			t.Errorf("ImportedValue(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
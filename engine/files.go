@@ -0,0 +1,46 @@
+package engine
+
+import "path/filepath"
+
+//ResourceFiles returns paths of resource files read by the last Load by locale ("def" for default one);
+//paths of os files are absolute, paths in other filesystems (see NewFromFS) are names in them
+func (l *Localizer) ResourceFiles() map[string]string {
+	res := make(map[string]string, len(l.loadedFiles))
+	for loc, fileName := range l.loadedFiles {
+		res[loc] = l.absPath(fileName)
+	}
+	return res
+}
+
+//TargetFiles returns paths of resource files Save would write by locale (see ResourceFiles): files of all
+//non-default locales (including pseudo-locale set by SetPseudoLocale) and default one if it is written too
+//(see SetWriteDefault and Rename)
+func (l *Localizer) TargetFiles() map[string]string {
+	res := map[string]string{}
+	if l.err != nil {
+		return res
+	}
+	for _, loc := range l.Locales {
+		if loc != defLocale {
+			res[loc] = l.absPath(l.getFileNameForLocale(loc))
+		}
+	}
+	if loc, err := NormalizeLocale(l.pseudoLocale); err == nil {
+		res[loc] = l.absPath(l.getFileNameForLocale(loc))
+	}
+	if l.writeDefault || len(l.renames) > 0 {
+		res[defLocale] = l.absPath(l.getFileNameForLocale(defLocale))
+	}
+	return res
+}
+
+// absPath returns absolute path of os file (name itself if it is not in os filesystem)
+func (l *Localizer) absPath(fileName string) string {
+	if _, ok := l.fsys.(osFS); !ok {
+		return l.fsName(fileName)
+	}
+	if abs, err := filepath.Abs(fileName); err == nil {
+		return abs
+	}
+	return fileName
+}
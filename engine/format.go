@@ -0,0 +1,395 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+//Format describes file format values may be exported to and imported from
+type Format struct {
+	Name string
+	//Extensions are file name extensions (with leading dot) format is guessed by
+	Extensions []string
+	Export     func(l *Localizer, w io.Writer) error
+	Import     func(l *Localizer, r io.Reader) error
+	//ExportDir exports values to files in dir (for formats with file per locale)
+	ExportDir func(l *Localizer, dir string) error
+	//ImportDir imports values from files in dir (for formats with file per locale)
+	ImportDir func(l *Localizer, dir string) error
+}
+
+var (
+	formats = map[string]*Format{}
+	// knownExtensions maps extensions to names of formats that may be registered by other packages
+	knownExtensions = map[string]string{".xlsx": "xlsx", ".po": "po", ".pot": "po"}
+)
+
+func init() {
+	RegisterFormat(&Format{
+		Name:       "csv",
+		Extensions: []string{".csv"},
+		Export:     (*Localizer).ExportW,
+		Import:     (*Localizer).ImportR,
+		ExportDir:  (*Localizer).ExportPerLocale,
+		ImportDir:  (*Localizer).ImportDir,
+	})
+	RegisterFormat(&Format{
+		Name:       "tsv",
+		Extensions: []string{".tsv", ".tab"},
+		Export: func(l *Localizer, w io.Writer) error {
+			return l.writeCSV(w, '\t', l.Locales[1:], false)
+		},
+		Import: func(l *Localizer, r io.Reader) error {
+			return l.readCSV(r, '\t', l.importLocales)
+		},
+	})
+	RegisterFormat(&Format{
+		Name:       "json",
+		Extensions: []string{".json"},
+		Export:     (*Localizer).ExportJSONW,
+		Import:     (*Localizer).ImportJSONR,
+	})
+	RegisterFormat(&Format{
+		Name:       "ndjson",
+		Extensions: []string{".ndjson", ".jsonl"},
+		Export:     (*Localizer).ExportNDJSONW,
+		Import:     (*Localizer).ImportNDJSONR,
+	})
+	RegisterFormat(&Format{
+		Name:       "arb",
+		Extensions: []string{".arb"},
+		ExportDir:  (*Localizer).ExportARB,
+		ImportDir:  (*Localizer).ImportARB,
+	})
+	RegisterFormat(&Format{
+		Name:       "apple",
+		Extensions: []string{".strings"},
+		ExportDir:  (*Localizer).ExportAppleStrings,
+	})
+	RegisterFormat(&Format{
+		Name:       "properties",
+		Extensions: []string{".properties"},
+		ExportDir:  (*Localizer).ExportProperties,
+		ImportDir:  (*Localizer).ImportPropertiesDir,
+	})
+	RegisterFormat(&Format{
+		Name:       "xliff",
+		Extensions: []string{".xlf", ".xliff"},
+		ExportDir:  (*Localizer).ExportXLIFF,
+		ImportDir:  (*Localizer).ImportXLIFF,
+	})
+	RegisterFormat(&Format{
+		Name:   "model",
+		Export: (*Localizer).DumpJSON,
+	})
+}
+
+//RegisterFormat registers format (replacing registered earlier one with the same name)
+func RegisterFormat(f *Format) {
+	formats[f.Name] = f
+}
+
+//Formats returns sorted names of registered formats
+func Formats() []string {
+	names := make([]string, 0, len(formats))
+	for n := range formats {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+//FormatByName returns registered format with given name
+func FormatByName(name string) (*Format, error) {
+	f, ok := formats[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported format '%s'; supported formats: %s", name, strings.Join(Formats(), ", "))
+	}
+	return f, nil
+}
+
+//FormatForFile returns format with given name or, if name is empty, guesses it by file extension
+//(.gz is skipped: data.csv.gz is csv)
+func FormatForFile(fileName string, name string) (*Format, error) {
+	if name != "" {
+		return FormatByName(name)
+	}
+	if isGzip(fileName) {
+		fileName = fileName[:len(fileName)-len(gzipExt)]
+	}
+	ext := strings.ToLower(filepath.Ext(fileName))
+	for _, f := range formats {
+		for _, e := range f.Extensions {
+			if e == ext {
+				return f, nil
+			}
+		}
+	}
+	if ext == "" {
+		return nil, fmt.Errorf("can not guess format of '%s'; supported formats: %s", fileName, strings.Join(Formats(), ", "))
+	}
+	if name, ok := knownExtensions[ext]; ok {
+		return FormatByName(name)
+	}
+	return FormatByName(ext[1:])
+}
+
+//ExportFile exports data to file in given format (guessed by file extension if empty);
+//file is compressed with gzip if its name ends with .gz
+func (l *Localizer) ExportFile(fileName string, format string) error {
+	if l.err != nil {
+		return l.err
+	}
+	f, err := FormatForFile(fileName, format)
+	if err != nil {
+		return err
+	}
+	if f.Export == nil {
+		return fmt.Errorf("format '%s' can not be exported to file, export it to dir", f.Name)
+	}
+	return l.exportFile(fileName, func(w io.Writer) error {
+		return f.Export(l, w)
+	})
+}
+
+//ExportToDir exports data to files in dir in given format
+func (l *Localizer) ExportToDir(dir string, format string) error {
+	if l.err != nil {
+		return l.err
+	}
+	f, err := FormatByName(format)
+	if err != nil {
+		return err
+	}
+	if f.ExportDir == nil {
+		return fmt.Errorf("format '%s' can not be exported to dir, export it to file", f.Name)
+	}
+	return f.ExportDir(l, dir)
+}
+
+//ImportFromDir imports data from files in dir in given format
+func (l *Localizer) ImportFromDir(dir string, format string) error {
+	if l.err != nil {
+		return l.err
+	}
+	f, err := FormatByName(format)
+	if err != nil {
+		return err
+	}
+	if f.ImportDir == nil {
+		return fmt.Errorf("format '%s' can not be imported from dir, import it from file", f.Name)
+	}
+	return f.ImportDir(l, dir)
+}
+
+//ImportFile imports data from file in given format (guessed by file extension if empty);
+//file is decompressed with gzip if its name ends with .gz
+func (l *Localizer) ImportFile(fileName string, format string) error {
+	if l.err != nil {
+		return l.err
+	}
+	f, err := FormatForFile(fileName, format)
+	if err != nil {
+		return err
+	}
+	if f.Import == nil {
+		return fmt.Errorf("import from format '%s' is not supported", f.Name)
+	}
+	inf, err := l.openImportFile(fileName)
+	if err != nil {
+		return err
+	}
+	defer inf.Close()
+	return f.Import(l, inf)
+}
+
+type jsonString struct {
+	Name         string            `json:"name"`
+	Translatable bool              `json:"translatable"`
+	Values       map[string]string `json:"values"`
+	Status       map[string]string `json:"status,omitempty"`
+}
+
+//ExportJSONW writes translatable strings to w as json array of objects with name and values by locale
+func (l *Localizer) ExportJSONW(w io.Writer) error {
+	if l.err != nil {
+		return l.err
+	}
+	res := []jsonString{}
+	for _, n := range l.sortedNames() {
+		s := l.strings[n]
+		if l.isTranslatable(s) {
+			res = append(res, l.jsonString(s))
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", xmlIndent)
+	enc.SetEscapeHTML(false)
+	return enc.Encode(res)
+}
+
+//ImportJSONR imports values from json written by ExportJSONW
+func (l *Localizer) ImportJSONR(r io.Reader) error {
+	if l.err != nil {
+		return l.err
+	}
+	var res []jsonString
+	err := json.NewDecoder(r).Decode(&res)
+	if err != nil {
+		return fmt.Errorf("invalid json format: %w", err)
+	}
+	// all the records are checked before applying so invalid file changes nothing
+	for i := range res {
+		if err = l.checkJSONString(&res[i]); err != nil {
+			return err
+		}
+	}
+	for _, js := range res {
+		l.applyJSONString(js)
+	}
+	return nil
+}
+
+//ExportNDJSONW writes translatable strings to w as newline-delimited json (one object like in ExportJSONW per line);
+//records are written one by one and w is flushed after every record if it has Flush method (e.g. *bufio.Writer)
+func (l *Localizer) ExportNDJSONW(w io.Writer) error {
+	if l.err != nil {
+		return l.err
+	}
+	flusher, _ := w.(interface{ Flush() error })
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, n := range l.sortedNames() {
+		s := l.strings[n]
+		if !l.isTranslatable(s) {
+			continue
+		}
+		if err := enc.Encode(l.jsonString(s)); err != nil {
+			return err
+		}
+		if flusher != nil {
+			if err := flusher.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+//ImportNDJSONR imports values from newline-delimited json written by ExportNDJSONW reading records one by one;
+//values are applied only after all the records are read and checked
+func (l *Localizer) ImportNDJSONR(r io.Reader) error {
+	if l.err != nil {
+		return l.err
+	}
+	dec := json.NewDecoder(r)
+	var res []jsonString
+	for rec := 1; ; rec++ {
+		var js jsonString
+		err := dec.Decode(&js)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("invalid ndjson format: record %d: %w", rec, err)
+		}
+		if err = l.checkJSONString(&js); err != nil {
+			return fmt.Errorf("record %d: %w", rec, err)
+		}
+		res = append(res, js)
+	}
+	for _, js := range res {
+		l.applyJSONString(js)
+	}
+	return nil
+}
+
+func (l *Localizer) jsonString(s *String) jsonString {
+	js := jsonString{Name: s.Name, Translatable: s.Translatable, Values: map[string]string{}, Status: s.Status}
+	for _, loc := range l.Locales {
+		if v, ok := s.Values[loc]; ok {
+			if l.jsonPlaceholders == NamedPlaceholders {
+				v = printfToNamed(v)
+			}
+			js.Values[loc] = editableValue(v)
+		}
+	}
+	return js
+}
+
+// checkJSONString checks that string of record is known and its locales are valid, converting them
+// to android qualifier form (so values are never written outside of resources dir)
+func (l *Localizer) checkJSONString(js *jsonString) error {
+	if l.isExcluded(js.Name) {
+		return nil
+	}
+	if _, ok := l.strings[js.Name]; !ok {
+		return &UnknownKeyError{Name: js.Name, Source: "json"}
+	}
+	values := make(map[string]string, len(js.Values))
+	for loc, v := range js.Values {
+		norm, err := l.jsonLocale(js.Name, loc)
+		if err != nil {
+			return err
+		}
+		values[norm] = v
+	}
+	js.Values = values
+	status := make(map[string]string, len(js.Status))
+	for loc, st := range js.Status {
+		norm, err := l.jsonLocale(js.Name, loc)
+		if err != nil {
+			return err
+		}
+		status[norm] = st
+	}
+	js.Status = status
+	return nil
+}
+
+// jsonLocale returns locale of json values key (def or locale name)
+func (l *Localizer) jsonLocale(name, key string) (string, error) {
+	if key == defLocale {
+		return key, nil
+	}
+	loc, ok := l.columnLocale(key)
+	if !ok {
+		return "", fmt.Errorf("%s: invalid locale '%s'", name, key)
+	}
+	return loc, nil
+}
+
+// applyJSONString applies values of record checked by checkJSONString
+func (l *Localizer) applyJSONString(js jsonString) {
+	if l.isExcluded(js.Name) {
+		return
+	}
+	s := l.strings[js.Name]
+	if s.IsReference() || !s.Translatable {
+		return
+	}
+	for loc, v := range js.Values {
+		if len(l.importLocales) > 0 && !contains(l.importLocales, loc) {
+			continue
+		}
+		if (loc != defLocale || l.writeDefault) && !l.keepsReference(s, loc, v) {
+			if l.jsonPlaceholders == NamedPlaceholders {
+				// unchanged value is kept as it is (conversion back would give indexes to %s placeholders)
+				if v == editableValue(printfToNamed(s.Values[loc])) {
+					v = s.Values[loc]
+				} else {
+					v = fromNamedPlaceholders(v, ParsePlaceholders(s.Values[defLocale]))
+				}
+			}
+			l.addLocale(loc)
+			s.Values[loc] = normalizeValue(l.importedNewlines(v), s.Values[loc], s.Values[defLocale])
+		}
+	}
+	for loc, st := range js.Status {
+		s.SetStatus(loc, st)
+	}
+}
@@ -0,0 +1,51 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestImportJSONRejectsInvalidLocale(t *testing.T) {
+	for _, format := range []string{"json", "ndjson"} {
+		t.Run(format, func(t *testing.T) {
+			l := loadProject(t, map[string]string{"values/strings.xml": testDefault}, "de")
+			f, err := FormatByName(format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			input := `{"name":"hello","values":{"de":"Hallo"}}` + "\n" +
+				`{"name":"bye","values":{"x/../../../../escape":"pwn"}}` + "\n"
+			if format == "json" {
+				input = "[" + strings.Replace(strings.TrimSpace(input), "\n", ",", -1) + "]"
+			}
+			if err = f.Import(l, strings.NewReader(input)); err == nil {
+				t.Fatal("invalid locale is accepted")
+			}
+			if v := l.Strings()["hello"].Values["de"]; v != "" {
+				t.Errorf("value of valid record is applied before error: %q", v)
+			}
+			if err = l.Save(); err != nil {
+				t.Fatal(err)
+			}
+			if _, err = os.Stat(filepath.Join(filepath.Dir(l.ResourcesDir), "escape")); err == nil {
+				t.Error("file is written outside of resources dir")
+			}
+			if len(l.Locales) != 2 {
+				t.Errorf("locales are %v", l.Locales)
+			}
+		})
+	}
+}
+
+func TestImportJSONNormalizesLocale(t *testing.T) {
+	l := loadProject(t, map[string]string{"values/strings.xml": testDefault})
+	err := l.ImportNDJSONR(strings.NewReader(`{"name":"hello","values":{"pt_BR":"Olá"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := l.Strings()["hello"].Values["pt-rBR"]; v != "Olá" {
+		t.Errorf("value of pt-rBR is %q", v)
+	}
+}
@@ -0,0 +1,226 @@
+package engine
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+//WriteFS is filesystem Save writes resource files to
+type WriteFS interface {
+	fs.FS
+	//MkdirAll creates dir with all the missing parents
+	MkdirAll(name string, perm fs.FileMode) error
+	//WriteFile replaces content of file (creating it with perm if needed); it should be atomic if possible
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+}
+
+//RemoveFS is filesystem files can be removed from (see CleanBackups)
+type RemoveFS interface {
+	WriteFS
+	//Remove removes file
+	Remove(name string) error
+}
+
+// osFS is default filesystem; unlike os.DirFS it takes os paths (relative or absolute) as names
+type osFS struct{}
+
+func (osFS) Open(name string) (fs.File, error) {
+	return os.Open(name)
+}
+
+func (osFS) MkdirAll(name string, perm fs.FileMode) error {
+	return os.MkdirAll(name, perm)
+}
+
+func (osFS) Remove(name string) error {
+	return os.Remove(name)
+}
+
+func (osFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return writeFile(name, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// writeFile atomically replaces file with content written by write: content is written to temporary file
+// in the same dir which is renamed to fileName (after beforeRename is called, if given) only if everything succeeded
+func writeFile(fileName string, write func(w io.Writer) error, beforeRename ...func() error) (err error) {
+	mode := os.FileMode(fileMode)
+	if fi, e := os.Stat(fileName); e == nil {
+		mode = fi.Mode().Perm()
+	}
+	f, err := ioutil.TempFile(filepath.Dir(fileName), "."+filepath.Base(fileName)+".*.tmp")
+	if err != nil {
+		return
+	}
+	tmpName := f.Name()
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(tmpName)
+		}
+	}()
+	if err = write(f); err != nil {
+		return
+	}
+	if err = f.Sync(); err != nil {
+		return
+	}
+	if err = f.Chmod(mode); err != nil {
+		return
+	}
+	if err = f.Close(); err != nil {
+		return
+	}
+	for _, br := range beforeRename {
+		if err = br(); err != nil {
+			return
+		}
+	}
+	return os.Rename(tmpName, fileName)
+}
+
+//NewFromFS creates localization engine reading project at root of fsys (e.g. "." for os.DirFS of project dir
+//or fstest.MapFS); resources are saved only if fsys implements WriteFS.
+// Files given by name to Export, Import and others are files of fsys too
+func NewFromFS(fsys fs.FS, root string, locales ...string) *Localizer {
+	l, _ := NewE(root, WithFS(fsys), WithLocales(locales...))
+	return l
+}
+
+// fsName converts path of resource file to name in engine's filesystem
+func (l *Localizer) fsName(name string) string {
+	if _, ok := l.fsys.(osFS); ok {
+		return name
+	}
+	return filepath.ToSlash(name)
+}
+
+func (l *Localizer) open(name string) (fs.File, error) {
+	return l.fsys.Open(l.fsName(name))
+}
+
+func (l *Localizer) readFile(name string) ([]byte, error) {
+	return fs.ReadFile(l.fsys, l.fsName(name))
+}
+
+func (l *Localizer) stat(name string) (fs.FileInfo, error) {
+	return fs.Stat(l.fsys, l.fsName(name))
+}
+
+func (l *Localizer) readDir(name string) ([]fs.DirEntry, error) {
+	return fs.ReadDir(l.fsys, l.fsName(name))
+}
+
+func (l *Localizer) writeFS() (WriteFS, error) {
+	wfs, ok := l.fsys.(WriteFS)
+	if !ok {
+		return nil, fmt.Errorf("resources filesystem is read-only")
+	}
+	return wfs, nil
+}
+
+func (l *Localizer) mkdirAll(name string) error {
+	wfs, err := l.writeFS()
+	if err != nil {
+		return err
+	}
+	return wfs.MkdirAll(l.fsName(name), dirMode)
+}
+
+func (l *Localizer) remove(name string) error {
+	rfs, ok := l.fsys.(RemoveFS)
+	if !ok {
+		return fmt.Errorf("files can not be removed from resources filesystem")
+	}
+	return rfs.Remove(l.fsName(name))
+}
+
+// writeResourceFile replaces file in engine's filesystem with data
+func (l *Localizer) writeResourceFile(name string, data []byte) error {
+	wfs, err := l.writeFS()
+	if err != nil {
+		return err
+	}
+	return wfs.WriteFile(l.fsName(name), data, fileMode)
+}
+
+// withFilesFS sets filesystem of files given by name to Export, Import and others
+// (engine's filesystem is used if it is not set)
+func withFilesFS(fsys fs.FS) Option {
+	return func(l *Localizer) {
+		l.filesFS = fsys
+	}
+}
+
+// files returns filesystem of files given by name to Export, Import and others
+func (l *Localizer) files() fs.FS {
+	if l.filesFS != nil {
+		return l.filesFS
+	}
+	return l.fsys
+}
+
+// filesName converts path of file given by name to name in its filesystem
+func (l *Localizer) filesName(name string) string {
+	if _, ok := l.files().(osFS); ok {
+		return name
+	}
+	return filepath.ToSlash(name)
+}
+
+// openFile opens file given by name (see files)
+func (l *Localizer) openFile(name string) (fs.File, error) {
+	return l.files().Open(l.filesName(name))
+}
+
+// readFileOf reads file given by name (see files)
+func (l *Localizer) readFileOf(name string) ([]byte, error) {
+	return fs.ReadFile(l.files(), l.filesName(name))
+}
+
+// glob returns names of files given by name (see files) matching pattern
+func (l *Localizer) glob(pattern string) ([]string, error) {
+	return fs.Glob(l.files(), l.filesName(pattern))
+}
+
+// filesWriteFS returns filesystem of files given by name if it is writable
+func (l *Localizer) filesWriteFS() (WriteFS, error) {
+	wfs, ok := l.files().(WriteFS)
+	if !ok {
+		return nil, fmt.Errorf("filesystem is read-only")
+	}
+	return wfs, nil
+}
+
+// mkdirAllOf creates dir for files given by name (see files)
+func (l *Localizer) mkdirAllOf(name string) error {
+	wfs, err := l.filesWriteFS()
+	if err != nil {
+		return err
+	}
+	return wfs.MkdirAll(l.filesName(name), dirMode)
+}
+
+// createFile writes file given by name (see files) with content written by write
+// (os files are written directly, other filesystems get the whole content at once)
+func (l *Localizer) createFile(name string, write func(w io.Writer) error) error {
+	if _, ok := l.files().(osFS); ok {
+		return writeFile(name, write)
+	}
+	wfs, err := l.filesWriteFS()
+	if err != nil {
+		return err
+	}
+	buf := &bytes.Buffer{}
+	if err = write(buf); err != nil {
+		return err
+	}
+	return wfs.WriteFile(l.filesName(name), buf.Bytes(), fileMode)
+}
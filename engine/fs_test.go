@@ -0,0 +1,65 @@
+package engine
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// memFS is in-memory writable filesystem
+type memFS struct {
+	fstest.MapFS
+}
+
+func (m memFS) MkdirAll(name string, perm fs.FileMode) error {
+	if _, ok := m.MapFS[name]; !ok {
+		m.MapFS[name] = &fstest.MapFile{Mode: fs.ModeDir | perm}
+	}
+	return nil
+}
+
+func (m memFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	m.MapFS[name] = &fstest.MapFile{Data: data, Mode: perm}
+	return nil
+}
+
+func TestInMemoryFS(t *testing.T) {
+	mem := memFS{fstest.MapFS{
+		"res/values/strings.xml":    {Data: []byte(testDefault)},
+		"res/values-de/strings.xml": {Data: []byte(testGerman)},
+	}}
+	l := NewFromFS(mem, "res").Load()
+	if err := l.Err(); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"out/strings.csv", "out/strings.csv.gz"} {
+		if err := l.ExportFile(name, ""); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if _, ok := mem.MapFS[name]; !ok {
+			t.Errorf("%s is not exported to filesystem", name)
+		}
+	}
+	if err := l.ExportToDir("xliff", "xliff"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := mem.MapFS["xliff/de.xlf"]; !ok {
+		t.Errorf("xliff is not exported to filesystem: %v", mem.MapFS)
+	}
+
+	csv := string(mem.MapFS["out/strings.csv"].Data)
+	mem.MapFS["in/strings.csv"] = &fstest.MapFile{Data: []byte(strings.Replace(csv, "Hallo", "Hallo!", 1))}
+	if err := l.ImportFile("in/strings.csv", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if de := string(mem.MapFS["res/values-de/strings.xml"].Data); !strings.Contains(de, ">Hallo!<") {
+		t.Errorf("imported value is not saved to filesystem:\n%s", de)
+	}
+	if _, err := l.ReadState("no-state.json"); err != nil {
+		t.Errorf("missing state file: %v", err)
+	}
+}
@@ -0,0 +1,173 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	gradleFiles         = []string{"build.gradle", "build.gradle.kts"}
+	gradleSettingsFiles = []string{"settings.gradle", "settings.gradle.kts"}
+	gradleIncludeRegexp = regexp.MustCompile(`^\s*include(Build)?\b(.*)`)
+	gradleResDirsRegexp = regexp.MustCompile(`(?:^|[^\w.]|\bmain\.)res\.(?:set)?[sS]rcDirs?\b(.*)`)
+	gradleQuotedRegexp  = regexp.MustCompile(`["']([^"']+)["']`)
+	gradleProjectDirVar = regexp.MustCompile(`^\$\{?projectDir\}?/`)
+	gradleRootDirVar    = regexp.MustCompile(`^\$\{?rootDir\}?/`)
+	// maxIncludedBuilds limits depth of included (composite) builds
+	maxIncludedBuilds = 4
+	// errNoDiscoveredResources is returned by discoveredResourcesDir if there are no modules with resources
+	errNoDiscoveredResources = errors.New("no resources are found in gradle modules")
+)
+
+//ResourceRoot is resources dir of gradle module
+type ResourceRoot struct {
+	//Module is gradle path of module without leading colon (e.g. app or feature:login)
+	Module string
+	//Dir is path of resources dir relative to project dir
+	Dir string
+}
+
+//SetModule sets gradle module (e.g. app) resources of which are processed if resources dir is discovered
+//by gradle configuration (see DiscoverResourceDirs) and looks for resources dir and locales again
+func (l *Localizer) SetModule(module string) *Localizer {
+	l.module = strings.TrimPrefix(module, ":")
+	l.init()
+	return l
+}
+
+//DiscoverResourceDirs reads settings.gradle (or settings.gradle.kts) of project for included modules
+//(and included builds) and their build.gradle (build.gradle.kts) for additional res.srcDirs; returns
+//existing resources dirs of modules (src/main/res goes first); configuration is not evaluated,
+//only plain string paths are recognized
+func (l *Localizer) DiscoverResourceDirs() ([]ResourceRoot, error) {
+	return l.discoverResourceDirs("", "", 0)
+}
+
+func (l *Localizer) discoverResourceDirs(buildDir, prefix string, depth int) ([]ResourceRoot, error) {
+	settings, err := l.readGradleFile(buildDir, gradleSettingsFiles)
+	if err != nil || settings == "" {
+		return nil, err
+	}
+	var roots []ResourceRoot
+	for _, line := range strings.Split(settings, "\n") {
+		m := gradleIncludeRegexp.FindStringSubmatch(stripGradleComment(line))
+		if m == nil {
+			continue
+		}
+		for _, q := range gradleQuotedRegexp.FindAllStringSubmatch(m[2], -1) {
+			if m[1] != "" {
+				// included (composite) build
+				if depth >= maxIncludedBuilds {
+					continue
+				}
+				dir := filepath.Join(buildDir, q[1])
+				included, err := l.discoverResourceDirs(dir, prefix+filepath.Base(dir)+":", depth+1)
+				if err != nil {
+					return nil, err
+				}
+				roots = append(roots, included...)
+				continue
+			}
+			module := strings.TrimPrefix(q[1], ":")
+			dirs, err := l.moduleResourceDirs(buildDir, filepath.Join(buildDir, filepath.FromSlash(strings.Replace(module, ":", "/", -1))))
+			if err != nil {
+				return nil, err
+			}
+			for _, dir := range dirs {
+				roots = append(roots, ResourceRoot{Module: prefix + module, Dir: dir})
+			}
+		}
+	}
+	return roots, nil
+}
+
+// moduleResourceDirs returns existing resources dirs of module (relative to project dir)
+func (l *Localizer) moduleResourceDirs(buildDir, moduleDir string) ([]string, error) {
+	dirs := []string{filepath.Join(moduleDir, "src", "main", "res")}
+	build, err := l.readGradleFile(moduleDir, gradleFiles)
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(build, "\n") {
+		m := gradleResDirsRegexp.FindStringSubmatch(stripGradleComment(line))
+		if m == nil {
+			continue
+		}
+		for _, q := range gradleQuotedRegexp.FindAllStringSubmatch(m[1], -1) {
+			dir := filepath.Join(moduleDir, filepath.FromSlash(gradleProjectDirVar.ReplaceAllString(q[1], "")))
+			if gradleRootDirVar.MatchString(q[1]) {
+				dir = filepath.Join(buildDir, filepath.FromSlash(gradleRootDirVar.ReplaceAllString(q[1], "")))
+			}
+			if !contains(dirs, dir) {
+				dirs = append(dirs, dir)
+			}
+		}
+	}
+	var res []string
+	for _, dir := range dirs {
+		if fi, err := l.stat(filepath.Join(l.projectDir, dir)); err == nil && fi.IsDir() {
+			res = append(res, dir)
+		}
+	}
+	return res, nil
+}
+
+// readGradleFile returns content of the first existing of files in dir (relative to project dir)
+func (l *Localizer) readGradleFile(dir string, files []string) (string, error) {
+	for _, name := range files {
+		content, err := l.readFile(filepath.Join(l.projectDir, dir, name))
+		if err == nil {
+			return string(content), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+	}
+	return "", nil
+}
+
+func stripGradleComment(line string) string {
+	if i := strings.Index(line, "//"); i >= 0 {
+		return line[:i]
+	}
+	return line
+}
+
+// discoveredResourcesDir returns resources dir (relative to project dir) discovered by gradle configuration
+// and name of its default values dir; resources dir of the module set by SetModule is returned
+// or the only one if there is no module set
+func (l *Localizer) discoveredResourcesDir() (string, string, error) {
+	roots, err := l.DiscoverResourceDirs()
+	if err != nil {
+		return "", "", err
+	}
+	var modules []string
+	res, def := "", ""
+	for _, r := range roots {
+		if l.module != "" && r.Module != l.module {
+			continue
+		}
+		dir, err := l.checkPathIsResourcesDir(filepath.Join(l.projectDir, r.Dir))
+		if err != nil || contains(modules, r.Module) {
+			// the first resources dir of module with strings is used
+			continue
+		}
+		modules = append(modules, r.Module)
+		if res == "" {
+			res, def = r.Dir, dir
+		}
+	}
+	switch {
+	case len(modules) > 1:
+		return "", "", fmt.Errorf("resources are found in several gradle modules (%s): choose one of them", strings.Join(modules, ", "))
+	case res == "" && l.module != "":
+		return "", "", fmt.Errorf("no resources with %s are found in module '%s'", l.stringsFileName(), l.module)
+	case res == "":
+		return "", "", errNoDiscoveredResources
+	}
+	return res, def, nil
+}
@@ -0,0 +1,52 @@
+package engine
+
+import (
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"strings"
+)
+
+const gzipExt = ".gz"
+
+func isGzip(fileName string) bool {
+	return strings.HasSuffix(strings.ToLower(fileName), gzipExt)
+}
+
+// exportFile writes file with write compressing content if file name ends with .gz
+func (l *Localizer) exportFile(fileName string, write func(w io.Writer) error) error {
+	if !isGzip(fileName) {
+		return l.createFile(fileName, write)
+	}
+	return l.createFile(fileName, func(w io.Writer) error {
+		zw := gzip.NewWriter(w)
+		if err := write(zw); err != nil {
+			return err
+		}
+		return zw.Close()
+	})
+}
+
+// openImportFile opens file decompressing it if file name ends with .gz
+func (l *Localizer) openImportFile(fileName string) (io.ReadCloser, error) {
+	f, err := l.openFile(fileName)
+	if err != nil || !isGzip(fileName) {
+		return f, err
+	}
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &gzipFile{Reader: zr, f: f}, nil
+}
+
+type gzipFile struct {
+	*gzip.Reader
+	f fs.File
+}
+
+func (g *gzipFile) Close() error {
+	g.Reader.Close()
+	return g.f.Close()
+}
@@ -0,0 +1,305 @@
+package engine
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// icuComplexArgRegexp finds ICU arguments with plural or select style (marker of ICU MessageFormat value)
+var icuComplexArgRegexp = regexp.MustCompile(`\{\s*[\p{L}\p{N}_]+\s*,\s*(plural|select|selectordinal)\s*,`)
+
+// icuSimpleTypes are types of ICU arguments with optional style without nested messages
+var icuSimpleTypes = map[string]bool{"number": true, "date": true, "time": true, "spellout": true, "ordinal": true, "duration": true}
+
+//ICUError describes value of string that is not valid ICU MessageFormat or has other arguments than default value
+type ICUError struct {
+	Name   string
+	Locale string
+	Err    error
+}
+
+func (e *ICUError) Error() string {
+	return fmt.Sprintf("%s: value for '%s' is invalid ICU message: %v", e.Name, e.Locale, e.Err)
+}
+
+func (e *ICUError) Unwrap() error {
+	return e.Err
+}
+
+//SetICUPatterns sets name patterns (path.Match globs, e.g. "icu_*") of strings which values are ICU MessageFormat
+//messages; strings with plural or select arguments ({count, plural, ...}) are treated as ICU ones anyway
+func (l *Localizer) SetICUPatterns(patterns ...string) *Localizer {
+	l.icuPatterns = patterns
+	return l
+}
+
+func (l *Localizer) isICU(s *String) bool {
+	return matchesAny(s.Name, l.icuPatterns) || icuComplexArgRegexp.MatchString(PlainText(s.Values[defLocale]))
+}
+
+//ValidateICU parses values of ICU MessageFormat strings (see SetICUPatterns) in every locale
+//and returns *ICUError for every syntax error and for values which argument names differ from default value's
+func (l *Localizer) ValidateICU() []error {
+	if l.err != nil {
+		return []error{l.err}
+	}
+	var errs []error
+	for _, n := range l.sortedNames() {
+		s := l.strings[n]
+		if !l.isTranslatable(s) || !l.isICU(s) {
+			continue
+		}
+		defArgs, err := ParseICUArguments(PlainText(s.Values[defLocale]))
+		if err != nil {
+			errs = append(errs, &ICUError{Name: n, Locale: defLocale, Err: err})
+			continue
+		}
+		for _, loc := range l.Locales[1:] {
+			v, ok := s.Values[loc]
+			if !ok || v == "" {
+				continue
+			}
+			args, err := ParseICUArguments(PlainText(v))
+			if err == nil && strings.Join(args, ",") != strings.Join(defArgs, ",") {
+				err = fmt.Errorf("arguments %v instead of %v", args, defArgs)
+			}
+			if err != nil {
+				errs = append(errs, &ICUError{Name: n, Locale: loc, Err: err})
+			}
+		}
+	}
+	return errs
+}
+
+//ParseICUArguments checks syntax of ICU MessageFormat message and returns sorted names of its arguments
+func ParseICUArguments(message string) ([]string, error) {
+	p, err := parseICU(message, false)
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, 0, len(p.args))
+	for a := range p.args {
+		args = append(args, a)
+	}
+	sort.Strings(args)
+	return args, nil
+}
+
+// icuTextMask returns mask of runes of ICU message that are message text (not arguments' syntax)
+func icuTextMask(message string) ([]bool, error) {
+	p, err := parseICU(message, true)
+	if err != nil {
+		return nil, err
+	}
+	return p.textMask, nil
+}
+
+func parseICU(message string, mask bool) (*icuParser, error) {
+	p := &icuParser{text: []rune(message), args: map[string]bool{}}
+	if mask {
+		p.textMask = make([]bool, len(p.text))
+	}
+	if err := p.message(0); err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.text) {
+		return nil, p.errorf("unmatched '}'")
+	}
+	return p, nil
+}
+
+type icuParser struct {
+	text []rune
+	pos  int
+	args map[string]bool
+	// textMask marks runes of message text if it is not nil
+	textMask []bool
+}
+
+func (p *icuParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("position %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+// message parses message text up to '}' closing nested message (depth > 0) or to the end
+func (p *icuParser) message(depth int) error {
+	for p.pos < len(p.text) {
+		switch p.text[p.pos] {
+		case '\'':
+			start := p.pos
+			p.quoted()
+			p.markText(start)
+		case '{':
+			if err := p.argument(depth); err != nil {
+				return err
+			}
+		case '}':
+			if depth == 0 {
+				return p.errorf("unmatched '}'")
+			}
+			return nil
+		case '#':
+			// number of plural argument
+			if depth == 0 {
+				p.markText(p.pos)
+			}
+			p.pos++
+		default:
+			p.markText(p.pos)
+			p.pos++
+		}
+	}
+	if depth > 0 {
+		return p.errorf("unclosed '{'")
+	}
+	return nil
+}
+
+// markText marks runes from start up to the current position (at least one) as message text
+func (p *icuParser) markText(start int) {
+	if p.textMask == nil {
+		return
+	}
+	for i := start; i == start || i < p.pos; i++ {
+		p.textMask[i] = true
+	}
+}
+
+// quoted skips apostrophe: '' is literal apostrophe, apostrophe before special character starts quoted text
+func (p *icuParser) quoted() {
+	p.pos++
+	if p.pos < len(p.text) && p.text[p.pos] == '\'' {
+		p.pos++
+		return
+	}
+	if p.pos >= len(p.text) || !strings.ContainsRune("{}#|", p.text[p.pos]) {
+		return
+	}
+	for p.pos < len(p.text) {
+		if p.text[p.pos] == '\'' {
+			if p.pos+1 < len(p.text) && p.text[p.pos+1] == '\'' {
+				p.pos += 2
+				continue
+			}
+			p.pos++
+			return
+		}
+		p.pos++
+	}
+}
+
+// argument parses argument starting with '{'
+func (p *icuParser) argument(depth int) error {
+	p.pos++
+	name := p.word()
+	if name == "" {
+		return p.errorf("argument name expected")
+	}
+	p.args[name] = true
+	if p.consume('}') {
+		return nil
+	}
+	if !p.consume(',') {
+		return p.errorf("',' or '}' expected after argument '%s'", name)
+	}
+	typ := p.word()
+	switch {
+	case typ == "plural" || typ == "selectordinal" || typ == "select":
+		if !p.consume(',') {
+			return p.errorf("',' expected after '%s'", typ)
+		}
+		return p.options(typ, depth)
+	case icuSimpleTypes[typ]:
+		if p.consume('}') {
+			return nil
+		}
+		if !p.consume(',') {
+			return p.errorf("',' or '}' expected after '%s'", typ)
+		}
+		// style (e.g. "integer" or skeleton) is not checked
+		for level := 0; p.pos < len(p.text); p.pos++ {
+			switch p.text[p.pos] {
+			case '{':
+				level++
+			case '}':
+				if level == 0 {
+					p.pos++
+					return nil
+				}
+				level--
+			}
+		}
+		return p.errorf("unclosed '{'")
+	case typ == "":
+		return p.errorf("argument type expected")
+	}
+	return p.errorf("unknown argument type '%s'", typ)
+}
+
+// options parses selectors with messages of plural or select argument up to closing '}'
+func (p *icuParser) options(typ string, depth int) error {
+	selectors := map[string]bool{}
+	for {
+		p.skipSpaces()
+		if p.consume('}') {
+			break
+		}
+		if p.pos >= len(p.text) {
+			return p.errorf("unclosed '{'")
+		}
+		selector := p.word()
+		if typ != "select" && strings.HasPrefix(selector, "offset:") && len(selectors) == 0 {
+			continue
+		}
+		if selector == "" {
+			return p.errorf("selector expected in %s", typ)
+		}
+		if selectors[selector] {
+			return p.errorf("selector '%s' is given twice", selector)
+		}
+		selectors[selector] = true
+		if !p.consume('{') {
+			return p.errorf("'{' expected after selector '%s'", selector)
+		}
+		if err := p.message(depth + 1); err != nil {
+			return err
+		}
+		p.pos++
+	}
+	if !selectors["other"] {
+		return p.errorf("%s has no 'other' selector", typ)
+	}
+	return nil
+}
+
+// word skips spaces and returns the following word (sequence of letters, digits and _:=+-.)
+func (p *icuParser) word() string {
+	p.skipSpaces()
+	start := p.pos
+	for p.pos < len(p.text) {
+		c := p.text[p.pos]
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) && !strings.ContainsRune("_:=+-.", c) {
+			break
+		}
+		p.pos++
+	}
+	return string(p.text[start:p.pos])
+}
+
+// consume skips spaces and c if it is the next character
+func (p *icuParser) consume(c rune) bool {
+	p.skipSpaces()
+	if p.pos < len(p.text) && p.text[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *icuParser) skipSpaces() {
+	for p.pos < len(p.text) && unicode.IsSpace(p.text[p.pos]) {
+		p.pos++
+	}
+}
@@ -0,0 +1,123 @@
+package engine
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+)
+
+var (
+	stringTagRegexp    = regexp.MustCompile(`<(string-array|plurals|string)\b[^>]*>`)
+	nameAttrRegexp     = regexp.MustCompile(`\bname\s*=\s*"([^"]*)"`)
+	translatableRegexp = regexp.MustCompile(`\btranslatable\s*=\s*"[^"]*"`)
+)
+
+//IdenticalAcrossLocales returns sorted names of translatable strings which values in all the locales
+//that have them are the same as default one (proper nouns or values left untranslated)
+func (l *Localizer) IdenticalAcrossLocales() []string {
+	names := []string{}
+	if l.err != nil {
+		return names
+	}
+	for _, n := range l.sortedNames() {
+		s := l.strings[n]
+		def := s.Values[defLocale]
+		if !l.isTranslatable(s) || def == "" {
+			continue
+		}
+		present := 0
+		identical := true
+		for _, loc := range l.Locales[1:] {
+			v, ok := s.Values[loc]
+			if !ok || v == "" {
+				continue
+			}
+			present++
+			if v != def {
+				identical = false
+				break
+			}
+		}
+		if identical && present > 0 {
+			names = append(names, n)
+		}
+	}
+	return names
+}
+
+//MarkNoTranslate sets translatable="false" for strings with given names in default resources file
+//(the rest of the file is kept as is); next Save removes them from locale files
+func (l *Localizer) MarkNoTranslate(names ...string) error {
+	if l.err != nil {
+		return l.err
+	}
+	for _, n := range names {
+		if _, ok := l.strings[n]; !ok {
+			return fmt.Errorf("string '%s' not found", n)
+		}
+	}
+	fileName := l.getFileNameForLocale(defLocale)
+	content, err := l.readFile(fileName)
+	if err != nil {
+		return err
+	}
+	err = l.writeContent(fileName, markNoTranslate(content, names))
+	if err != nil {
+		return err
+	}
+	for _, n := range names {
+		if res, _, ok := splitItemName(n); ok {
+			// translatable attribute belongs to the whole plurals or string-array element
+			for _, item := range l.itemNames(res) {
+				l.strings[item].Translatable = false
+			}
+		}
+		l.strings[n].Translatable = false
+		l.logf("%s marked as not translatable", n)
+	}
+	return nil
+}
+
+// markNoTranslate adds (or replaces) translatable="false" attribute of string elements with given names
+// (and of plurals and string-array elements of given item names)
+func markNoTranslate(content []byte, names []string) []byte {
+	marked := map[string]bool{}
+	markedItems := map[string]bool{}
+	for _, n := range names {
+		if res, _, ok := splitItemName(n); ok {
+			markedItems[res] = true
+		} else {
+			marked[n] = true
+		}
+	}
+	return stringTagRegexp.ReplaceAllFunc(content, func(tag []byte) []byte {
+		m := nameAttrRegexp.FindSubmatch(tag)
+		if m == nil {
+			return tag
+		}
+		names := marked
+		if string(stringTagRegexp.FindSubmatch(tag)[1]) != "string" {
+			names = markedItems
+		}
+		if !names[string(m[1])] {
+			return tag
+		}
+		return setNoTranslate(tag)
+	})
+}
+
+// setNoTranslate adds (or replaces) translatable="false" attribute of string start tag
+func setNoTranslate(tag []byte) []byte {
+	if translatableRegexp.Match(tag) {
+		return translatableRegexp.ReplaceAll(tag, []byte(`translatable="false"`))
+	}
+	end := len(tag) - 1
+	if bytes.HasSuffix(tag, []byte("/>")) {
+		end--
+	}
+	var b bytes.Buffer
+	b.Write(bytes.TrimRight(tag[:end], " "))
+	b.WriteString(` translatable="false"`)
+	b.Write(tag[end:])
+	return b.Bytes()
+}
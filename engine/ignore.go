@@ -0,0 +1,68 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//IgnoreFileName is name of file with exclusion rules in resources dir: every line is name pattern
+//of strings (e.g. config_*) or, if it ends with .xml, name glob of resource files in default values dir
+//strings of which are never translated; empty lines and lines starting with # are skipped
+const IgnoreFileName = ".localizerignore"
+
+// defaultExcludeFiles are resource files of strings that are never translated by android convention
+var defaultExcludeFiles = []string{"donottranslate.xml"}
+
+//SetExcludeFiles sets name globs (path.Match, e.g. "*_constants.xml") of resource files in default values dir
+//strings of which are never translated (donottranslate.xml by default): Load reads names of their strings
+//which are then excluded like ones matching patterns of SetExcludePatterns
+func (l *Localizer) SetExcludeFiles(globs ...string) *Localizer {
+	l.excludeFiles = globs
+	return l
+}
+
+// loadExclusions reads rules of ignore file and names of strings of excluded files of default values dir
+func (l *Localizer) loadExclusions() {
+	l.ignorePatterns = nil
+	l.excludedNames = map[string]bool{}
+	files := defaultExcludeFiles
+	if l.excludeFiles != nil {
+		files = l.excludeFiles
+	}
+	fileName := filepath.Join(l.ResourcesDir, IgnoreFileName)
+	content, err := l.readFile(fileName)
+	if err != nil && !os.IsNotExist(err) {
+		l.warnings = append(l.warnings, &FileError{FileName: fileName, Err: err})
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case strings.HasSuffix(line, ".xml"):
+			// defaults are not modified
+			files = append(files[:len(files):len(files)], line)
+		default:
+			l.ignorePatterns = append(l.ignorePatterns, line)
+		}
+	}
+	dir := filepath.Join(l.ResourcesDir, l.defaultDir)
+	entries, err := l.readDir(dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if e.IsDir() || e.Name() == l.stringsFileName() || !matchesAny(e.Name(), files) {
+			continue
+		}
+		fileName := filepath.Join(dir, e.Name())
+		res, err := l.readResources(fileName)
+		if err != nil {
+			l.warnings = append(l.warnings, &FileError{FileName: fileName, Err: err})
+			continue
+		}
+		for _, s := range res.Strings {
+			l.excludedNames[s.Name] = true
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package engine
+
+import "regexp"
+
+// xmlDeclaration is declaration Android Studio starts resource files with
+const xmlDeclaration = `<?xml version="1.0" encoding="utf-8"?>` + "\n"
+
+var indentRegexp = regexp.MustCompile(`(?m)^([ \t]+)<`)
+
+//SetIndent sets indentation of elements of written locale resource files (e.g. "    " or "\t");
+//by default indentation of the existing file (or of default resources file for a new one) is kept
+func (l *Localizer) SetIndent(indent string) *Localizer {
+	l.indent = indent
+	return l
+}
+
+// indentFor returns indentation for elements of resources file
+func (l *Localizer) indentFor(fileName string) string {
+	if l.indent != "" {
+		return l.indent
+	}
+	for _, name := range []string{fileName, l.getFileNameForLocale(defLocale)} {
+		if content, err := l.readFile(name); err == nil {
+			if indent := detectIndent(content); indent != "" {
+				return indent
+			}
+		}
+	}
+	return xmlIndent
+}
+
+// detectIndent returns indentation of the first indented element of content
+func detectIndent(content []byte) string {
+	if m := indentRegexp.FindSubmatch(content); m != nil {
+		return string(m[1])
+	}
+	return ""
+}
@@ -0,0 +1,220 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// itemSeparator separates name of plurals or string-array from quantity or index in names of their items
+// (e.g. days:one, planets:2); names of android resources may not contain it, so names are unambiguous
+const itemSeparator = ":"
+
+// elements of resources with items
+const (
+	pluralsElement = "plurals"
+	arrayElement   = "string-array"
+)
+
+//PluralQuantities are quantities allowed in android plurals
+var PluralQuantities = []string{"zero", "one", "two", "few", "many", "other"}
+
+//ItemError is returned by csv import for rows of plurals or string-array items (name:quantity, name:index)
+//that can not be imported
+type ItemError struct {
+	Name string
+	Item string
+	Err  error
+}
+
+func (e *ItemError) Error() string {
+	return fmt.Sprintf("%s%s%s: %v", e.Name, itemSeparator, e.Item, e.Err)
+}
+
+func (e *ItemError) Unwrap() error {
+	return e.Err
+}
+
+// xItems is plurals or string-array element
+type xItems struct {
+	XMLName      xml.Name
+	Name         string     `xml:"name,attr"`
+	Translatable string     `xml:"translatable,attr,omitempty"`
+	Attrs        []xml.Attr `xml:",any,attr"`
+	Items        []xItem    `xml:"item"`
+}
+
+// Value keeps raw inner xml of item like xString does
+type xItem struct {
+	Quantity string `xml:"quantity,attr,omitempty"`
+	Value    string `xml:",innerxml"`
+}
+
+// splitItemName splits name of plurals or string-array item into resource name and quantity or index
+func splitItemName(name string) (res, item string, ok bool) {
+	i := strings.LastIndex(name, itemSeparator)
+	if i <= 0 {
+		return name, "", false
+	}
+	return name[:i], name[i+1:], true
+}
+
+// isArrayItem returns true if item of name:item is string-array item (index) rather than plurals one (quantity)
+func isArrayItem(item string) bool {
+	_, err := strconv.Atoi(item)
+	return err == nil
+}
+
+// flattenItems returns items of plurals or string-array element as strings named name:quantity or name:index
+// with attributes and comment of the element
+func flattenItems(x *xItems, comment string) []xString {
+	var res []xString
+	for i, it := range x.Items {
+		item := strconv.Itoa(i)
+		if x.XMLName.Local == pluralsElement {
+			item = it.Quantity
+		}
+		res = append(res, xString{Name: x.Name + itemSeparator + item, Value: it.Value, Translatable: x.Translatable,
+			Attrs: x.Attrs, Comment: comment})
+	}
+	return res
+}
+
+// writeItems writes plurals or string-array element of items (flattened by flattenItems) going first
+// in list; returns count of written items
+func writeItems(out *bytes.Buffer, indent string, list []xString) int {
+	name, item, _ := splitItemName(list[0].Name)
+	element := pluralsElement
+	if isArrayItem(item) {
+		element = arrayElement
+	}
+	count := 0
+	for count < len(list) {
+		if n, _, ok := splitItemName(list[count].Name); !ok || n != name {
+			break
+		}
+		count++
+	}
+	items := append([]xString{}, list[:count]...)
+	sort.SliceStable(items, func(i, j int) bool {
+		return itemOrder(items[i].Name) < itemOrder(items[j].Name)
+	})
+	attrs := []xml.Attr{{Name: xml.Name{Local: "name"}, Value: name}}
+	if items[0].Translatable != "" {
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Local: "translatable"}, Value: items[0].Translatable})
+	}
+	if items[0].Comment != "" {
+		out.WriteString(indent + xmlComment(items[0].Comment) + "\n")
+	}
+	out.WriteString(indent)
+	writeStartTag(out, element, append(attrs, items[0].Attrs...))
+	out.WriteString("\n")
+	for _, it := range items {
+		out.WriteString(indent + indent)
+		if _, quantity, _ := splitItemName(it.Name); element == pluralsElement {
+			writeStartTag(out, "item", []xml.Attr{{Name: xml.Name{Local: "quantity"}, Value: quantity}})
+		} else {
+			out.WriteString("<item>")
+		}
+		out.WriteString(it.Value + "</item>\n")
+	}
+	out.WriteString(indent + "</" + element + ">\n")
+	return count
+}
+
+// itemOrder returns position of item among items of its element: index of string-array item
+// or position of plurals quantity in PluralQuantities
+func itemOrder(name string) int {
+	_, item, _ := splitItemName(name)
+	if i, err := strconv.Atoi(item); err == nil {
+		return i
+	}
+	for i, q := range PluralQuantities {
+		if q == item {
+			return i
+		}
+	}
+	return len(PluralQuantities)
+}
+
+// importedItem returns new string of plurals quantity given in import that is not in resources yet
+// (e.g. few for russian translation of plurals that has one and other in default resources) or nil if name
+// is not an item name of plurals or string-array; *ItemError is returned for invalid quantities and for indexes
+// of array items that are not in resources
+func (l *Localizer) importedItem(name string) (*String, error) {
+	res, item, ok := splitItemName(name)
+	if !ok {
+		return nil, nil
+	}
+	siblings := l.itemNames(res)
+	if len(siblings) == 0 {
+		return nil, nil
+	}
+	sibling := l.strings[siblings[0]]
+	_, sibItem, _ := splitItemName(sibling.Name)
+	switch {
+	case isArrayItem(sibItem):
+		return nil, &ItemError{Name: res, Item: item, Err: fmt.Errorf("string array has no such item")}
+	case !contains(PluralQuantities, item):
+		return nil, &ItemError{Name: res, Item: item,
+			Err: fmt.Errorf("invalid quantity: should be one of %s", strings.Join(PluralQuantities, ", "))}
+	}
+	s := &String{Name: name, Values: map[string]string{}, Translatable: sibling.Translatable, Comment: sibling.Comment,
+		order: sibling.order}
+	for loc, attrs := range sibling.attrs {
+		if s.attrs == nil {
+			s.attrs = map[string][]xml.Attr{}
+		}
+		s.attrs[loc] = attrs
+	}
+	return s, nil
+}
+
+// hasTranslatedItems returns true if string is item of plurals or string-array that has translation for locale
+// of any of its items: such elements are written with default values of missing items (an array with omitted
+// items would have wrong indexes, plurals without other quantity crash the app)
+func (l *Localizer) hasTranslatedItems(s *String, loc string) bool {
+	res, _, ok := splitItemName(s.Name)
+	if !ok {
+		return false
+	}
+	for _, n := range l.itemNames(res) {
+		if _, tr := l.translation(l.strings[n], loc); tr {
+			return true
+		}
+	}
+	return false
+}
+
+// itemNames returns names of items of plurals or string-array with given name
+func (l *Localizer) itemNames(res string) []string {
+	var names []string
+	for n := range l.strings {
+		if r, _, ok := splitItemName(n); ok && r == res {
+			names = append(names, n)
+		}
+	}
+	return names
+}
+
+// hasValues returns true if string has any non-empty value
+func (s *String) hasValues() bool {
+	for _, v := range s.Values {
+		if v != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// isForeignQuantity returns true if string is plurals quantity that is not in default resources (e.g. few
+// of russian translation) and locale has no value of it: such strings are not missing in the locale
+func isForeignQuantity(s *String, loc string) bool {
+	_, _, item := splitItemName(s.Name)
+	_, def := s.Values[defLocale]
+	_, ok := s.Values[loc]
+	return item && !def && !ok
+}
@@ -0,0 +1,122 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const testItemsDefault = `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <string name="hello">Hello</string>
+    <!-- count of days -->
+    <plurals name="days">
+        <item quantity="one">%d day</item>
+        <item quantity="other">%d days</item>
+    </plurals>
+    <string-array name="planets">
+        <item>Mercury</item>
+        <item>Venus</item>
+        <item>Earth</item>
+    </string-array>
+</resources>
+`
+
+const testItemsGerman = `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <plurals name="days">
+        <item quantity="one">%d Tag</item>
+    </plurals>
+</resources>
+`
+
+func TestItemsExport(t *testing.T) {
+	l := loadProject(t, map[string]string{"values/strings.xml": testItemsDefault, "values-de/strings.xml": testItemsGerman})
+	buf := bytes.Buffer{}
+	if err := l.ExportW(&buf); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := map[string][]string{}
+	for _, row := range rows[1:] {
+		values[row[0]] = row[1:]
+	}
+	for n, v := range map[string][]string{
+		"days:one":   {"%d day", "%d Tag"},
+		"days:other": {"%d days", ""},
+		"planets:0":  {"Mercury", ""},
+		"planets:2":  {"Earth", ""},
+	} {
+		if strings.Join(values[n], "|") != strings.Join(v, "|") {
+			t.Errorf("%s: exported %q instead of %q", n, values[n], v)
+		}
+	}
+}
+
+func TestItemsImportAndSave(t *testing.T) {
+	l := loadProject(t, map[string]string{"values/strings.xml": testItemsDefault, "values-de/strings.xml": testItemsGerman}, "de", "ru")
+	l.SetUntranslatedPolicy(UntranslatedOmit)
+	input := "id,def,de,ru\n" +
+		"days:other,%d days,%d Tage,%d дней\n" +
+		"days:few,,,%d дня\n" +
+		"planets:1,Venus,,Венера\n"
+	if err := l.ImportR(strings.NewReader(input)); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Save(); err != nil {
+		t.Fatal(err)
+	}
+	de := readProjectFile(t, l, "values-de/strings.xml")
+	if !strings.Contains(de, `    <plurals name="days">
+        <item quantity="one">%d Tag</item>
+        <item quantity="other">%d Tage</item>
+    </plurals>`) {
+		t.Errorf("plurals are not saved:\n%s", de)
+	}
+	if strings.Contains(de, "planets") || strings.Contains(de, "few") {
+		t.Errorf("untranslated items are saved:\n%s", de)
+	}
+	ru := readProjectFile(t, l, "values-ru/strings.xml")
+	for _, want := range []string{`<item quantity="few">%d дня</item>`, `    <string-array name="planets">
+        <item>Mercury</item>
+        <item>Венера</item>
+        <item>Earth</item>
+    </string-array>`} {
+		if !strings.Contains(ru, want) {
+			t.Errorf("%q is not found in\n%s", want, ru)
+		}
+	}
+
+	l = New(l.ResourcesDir).Load()
+	if err := l.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if v := l.Strings()["days:few"].Values["ru"]; v != "%d дня" {
+		t.Errorf("days:few is loaded as %q", v)
+	}
+	if missing := l.Missing("de"); strings.Join(missing, ",") != "hello,planets:0,planets:1,planets:2" {
+		t.Errorf("missing in de: %v", missing)
+	}
+	if err := l.CheckRoundTrip(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestItemsImportErrors(t *testing.T) {
+	l := loadProject(t, map[string]string{"values/strings.xml": testItemsDefault}, "de")
+	for _, name := range []string{"days:several", "planets:3"} {
+		err := l.ImportR(strings.NewReader("id,def,de\n" + name + ",x,y\n"))
+		var ie *ItemError
+		if !errors.As(err, &ie) {
+			t.Errorf("%s: error is %v", name, err)
+		}
+	}
+	if _, ok := l.Strings()["days:several"]; ok {
+		t.Error("invalid item is added")
+	}
+}
@@ -0,0 +1,220 @@
+package engine
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	localeRegexp     = regexp.MustCompile(`^([a-z]{2,3})(?:[-_]r?([A-Z]{2}|[0-9]{3}))?$`)
+	bcp47LocaleRegex = regexp.MustCompile(`^b\+[a-zA-Z0-9]+(\+[a-zA-Z0-9]+)*$`)
+)
+
+//NormalizeLocale checks that loc is valid locale name and converts it to android resources qualifier form
+//(e.g. "pt-BR" and "pt_BR" become "pt-rBR")
+func NormalizeLocale(loc string) (string, error) {
+	if bcp47LocaleRegex.MatchString(loc) {
+		return loc, nil
+	}
+	m := localeRegexp.FindStringSubmatch(loc)
+	if m == nil {
+		return "", fmt.Errorf("invalid locale '%s': should be like 'de', 'pt-rBR' or 'b+sr+Latn'", loc)
+	}
+	if m[2] == "" {
+		return m[1], nil
+	}
+	return m[1] + "-r" + m[2], nil
+}
+
+//LanguageTag converts android locale qualifier to BCP 47 language tag (e.g. pt-rBR to pt-BR, b+sr+Latn to sr-Latn)
+func LanguageTag(loc string) string {
+	m := localeRegexp.FindStringSubmatch(loc)
+	if m == nil {
+		return strings.Replace(strings.TrimPrefix(loc, "b+"), "+", "-", -1)
+	}
+	if m[2] == "" {
+		return m[1]
+	}
+	return m[1] + "-" + m[2]
+}
+
+//FallbackFunc returns locale missing values of loc are taken from ("" means default locale)
+type FallbackFunc func(loc string) string
+
+//ParentLocale returns language locale of regional one (e.g. "es" for "es-rMX") or "" if loc has no region;
+//it is the default FallbackFunc, matching android resources resolution
+func ParentLocale(loc string) string {
+	m := localeRegexp.FindStringSubmatch(loc)
+	if m == nil || m[2] == "" {
+		return ""
+	}
+	return m[1]
+}
+
+//SetFallbackFunc sets function used by Resolve and Save to find locale to take missing value from
+func (l *Localizer) SetFallbackFunc(f FallbackFunc) *Localizer {
+	l.fallback = f
+	return l
+}
+
+//SetFallback sets locale missing values of loc are taken from (e.g. "pt" for "pt-rBR" or "es" for "ca")
+//before the fallback function is asked; "" (or "def") means default locale
+func (l *Localizer) SetFallback(loc, from string) *Localizer {
+	if l.fallbacks == nil {
+		l.fallbacks = map[string]string{}
+	}
+	l.fallbacks[loc] = from
+	return l
+}
+
+// fallbackLocale returns locale missing values of loc are taken from
+func (l *Localizer) fallbackLocale(loc string) string {
+	if from, ok := l.fallbacks[loc]; ok {
+		return from
+	}
+	if l.fallback != nil {
+		return l.fallback(loc)
+	}
+	return ParentLocale(loc)
+}
+
+//Resolve returns value of string name for locale; if there is no value for locale (or it is empty)
+//fallback chain is walked (e.g. es-rMX -> es -> default locale)
+func (l *Localizer) Resolve(name, locale string) (string, bool) {
+	s, ok := l.strings[name]
+	if !ok {
+		return "", false
+	}
+	return l.resolve(s, locale)
+}
+
+func (l *Localizer) resolve(s *String, locale string) (string, bool) {
+	if v, ok := l.translation(s, locale); ok {
+		return v, true
+	}
+	v, ok := s.Values[defLocale]
+	return v, ok
+}
+
+// translation returns value of s for locale or for the first of its fallback locales that has it
+// (default locale is not asked)
+func (l *Localizer) translation(s *String, locale string) (string, bool) {
+	visited := map[string]bool{}
+	for loc := locale; loc != "" && loc != defLocale && !visited[loc]; loc = l.fallbackLocale(loc) {
+		if v, ok := s.Values[loc]; ok && v != "" {
+			return v, true
+		}
+		visited[loc] = true
+	}
+	return "", false
+}
+
+// columnLocale returns locale for csv column header if it is one of engine's locales or valid locale name
+func (l *Localizer) columnLocale(header string) (string, bool) {
+	for _, loc := range l.Locales[1:] {
+		if loc == header {
+			return loc, true
+		}
+	}
+	loc, err := NormalizeLocale(header)
+	return loc, err == nil
+}
+
+//CreateLocale creates values dir of locale with resources file without strings (written as locale files
+//are written by Save, see SetIndent) and adds locale to engine; if the file exists, error wrapping
+//ErrLocaleExists is returned
+func (l *Localizer) CreateLocale(loc string) error {
+	if l.err != nil {
+		return l.err
+	}
+	loc, err := NormalizeLocale(loc)
+	if err != nil {
+		return err
+	}
+	fileName := l.getFileNameForLocale(loc)
+	if _, err = l.stat(fileName); err == nil {
+		return &FileError{FileName: fileName, Err: ErrLocaleExists}
+	}
+	if err = l.makeLocaleDir(loc); err != nil {
+		return err
+	}
+	if err = l.writeResources(fileName, &xStrings{}); err != nil {
+		return err
+	}
+	l.addLocaleFrom(loc, LocaleRequested)
+	return nil
+}
+
+//LocaleSource tells how locale got to engine's Locales
+type LocaleSource int
+
+const (
+	//LocaleAdded is source of locales added by imports and other operations (e.g. GeneratePseudo)
+	LocaleAdded LocaleSource = iota
+	//LocaleRequested is source of locales given to New (or in project config), AddLocale and CreateLocale
+	LocaleRequested
+	//LocaleGuessed is source of locales found in resources dir (values-* dirs)
+	LocaleGuessed
+)
+
+//LocaleSource returns source of locale (LocaleAdded for default locale and unknown ones)
+func (l *Localizer) LocaleSource(loc string) LocaleSource {
+	return l.localeSources[loc]
+}
+
+//RequestedLocales returns locales that were requested explicitly (see LocaleRequested) in order of Locales
+func (l *Localizer) RequestedLocales() []string {
+	return l.localesFrom(LocaleRequested)
+}
+
+//GuessedLocales returns locales that were found in resources dir and not requested (see LocaleGuessed)
+//in order of Locales
+func (l *Localizer) GuessedLocales() []string {
+	return l.localesFrom(LocaleGuessed)
+}
+
+func (l *Localizer) localesFrom(source LocaleSource) []string {
+	res := []string{}
+	for _, loc := range l.Locales {
+		if loc != defLocale && l.localeSources[loc] == source {
+			res = append(res, loc)
+		}
+	}
+	return res
+}
+
+//SortLocales sorts android locale qualifiers in canonical order matching android resources resolution:
+//by language, then language before its more specific variants (es, b+es+419, es-rMX, es-rUS), then by
+//language tag; qualifiers of the same tag (es-rMX and b+es+MX) are ordered by name
+func SortLocales(locales []string) {
+	sort.SliceStable(locales, func(i, j int) bool {
+		a, b := strings.Split(LanguageTag(locales[i]), "-"), strings.Split(LanguageTag(locales[j]), "-")
+		if a[0] != b[0] {
+			return a[0] < b[0]
+		}
+		if len(a) != len(b) {
+			return len(a) < len(b)
+		}
+		if ta, tb := strings.Join(a, "-"), strings.Join(b, "-"); ta != tb {
+			return ta < tb
+		}
+		return locales[i] < locales[j]
+	})
+}
+
+//SetCanonicalLocales sets whether Locales (and so csv columns, locale files and reports) are kept
+//in canonical order (see SortLocales) instead of order of requested locales followed by found ones
+func (l *Localizer) SetCanonicalLocales(canonical bool) *Localizer {
+	l.canonicalLocales = canonical
+	l.sortLocales()
+	return l
+}
+
+// sortLocales sorts locales except default one if canonical order is set
+func (l *Localizer) sortLocales() {
+	if l.canonicalLocales && len(l.Locales) > 1 {
+		SortLocales(l.Locales[1:])
+	}
+}
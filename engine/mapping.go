@@ -0,0 +1,139 @@
+package engine
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+//Mapping maps columns of csv files of other tools (e.g. TMS exports) to columns of engine
+type Mapping struct {
+	//ID is header of column with names of strings (id if empty)
+	ID string
+	//Default is header of column with default values (there is no such column if empty)
+	Default string
+	//Locales maps locales to headers of their columns
+	Locales map[string]string
+}
+
+//ParseMapping parses mapping of columns like id=identifier,def=source_text,de=translation
+func ParseMapping(s string) (*Mapping, error) {
+	m := &Mapping{Locales: map[string]string{}}
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		kv := strings.SplitN(item, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" || strings.TrimSpace(kv[1]) == "" {
+			return nil, fmt.Errorf("invalid column mapping '%s': should be like id=identifier", item)
+		}
+		name, column := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+		switch name {
+		case nameColumn:
+			m.ID = column
+		case defLocale:
+			m.Default = column
+		default:
+			loc, err := NormalizeLocale(name)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := m.Locales[loc]; ok {
+				return nil, fmt.Errorf("column of locale '%s' is mapped twice", name)
+			}
+			m.Locales[loc] = column
+		}
+	}
+	return m, nil
+}
+
+//SetColumnMapping sets mapping of columns used by csv import and export (nil for own layout);
+//columns that are not mapped are ignored on import and are not written on export
+func (l *Localizer) SetColumnMapping(m *Mapping) *Localizer {
+	l.mapping = m
+	return l
+}
+
+//ImportWithMapping imports values in csv format from reader with columns mapped by m
+func (l *Localizer) ImportWithMapping(r io.Reader, m Mapping) error {
+	if l.err != nil {
+		return l.err
+	}
+	prev := l.mapping
+	l.mapping = &m
+	defer func() {
+		l.mapping = prev
+	}()
+	return l.ImportR(r)
+}
+
+// column returns header of column mapped to engine's column name (id, def or locale)
+func (m *Mapping) column(name string) (string, bool) {
+	switch name {
+	case nameColumn:
+		if m.ID == "" {
+			return nameColumn, true
+		}
+		return m.ID, true
+	case defLocale:
+		return m.Default, m.Default != ""
+	}
+	for loc, column := range m.Locales {
+		if n, err := NormalizeLocale(loc); err == nil && n == name {
+			return column, true
+		}
+	}
+	return "", false
+}
+
+// importHeader returns engine's names of columns of csv header ("" for columns that are not mapped)
+func (m *Mapping) importHeader(header []string) []string {
+	names := append([]string{nameColumn, defLocale}, localesOf(m.Locales)...)
+	res := make([]string, len(header))
+	for i, h := range header {
+		for _, n := range names {
+			if column, ok := m.column(n); ok && column == h {
+				res[i] = n
+				break
+			}
+		}
+	}
+	return res
+}
+
+// exportColumns returns indexes of mapped columns of engine's csv header and their headers
+func (m *Mapping) exportColumns(header []string) ([]int, []string) {
+	var cols []int
+	var res []string
+	for i, h := range header {
+		if column, ok := m.column(h); ok {
+			cols = append(cols, i)
+			res = append(res, column)
+		}
+	}
+	return cols, res
+}
+
+func localesOf(columns map[string]string) []string {
+	locales := make([]string, 0, len(columns))
+	for loc := range columns {
+		if n, err := NormalizeLocale(loc); err == nil {
+			loc = n
+		}
+		locales = append(locales, loc)
+	}
+	return locales
+}
+
+// pick returns values of row by indexes (row itself if cols is nil)
+func pick(row []string, cols []int) []string {
+	if cols == nil {
+		return row
+	}
+	res := make([]string, len(cols))
+	for i, c := range cols {
+		res[i] = row[c]
+	}
+	return res
+}
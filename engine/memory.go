@@ -0,0 +1,65 @@
+package engine
+
+import (
+	"sort"
+	"strings"
+)
+
+//StatusMemory is status of values filled from translation memory
+const StatusMemory = "memory"
+
+//TMReport contains results of ApplyTranslationMemory
+type TMReport struct {
+	//Filled is count of filled values by locale
+	Filled map[string]int
+	//Conflicts contains default values that have different translations by locale
+	Conflicts map[string][]string
+}
+
+//ApplyTranslationMemory fills missing values of translatable strings for given locales (all if none given)
+//with existing translations of strings that have the same default value (ignoring leading and trailing
+//whitespace); values equal to default ones are treated as missing; default values translated differently in the same locale are reported as conflicts
+//and are not used. Filled values get StatusMemory status
+func (l *Localizer) ApplyTranslationMemory(locales ...string) TMReport {
+	rep := TMReport{Filled: map[string]int{}, Conflicts: map[string][]string{}}
+	if l.err != nil {
+		return rep
+	}
+	if len(locales) == 0 {
+		locales = l.Locales[1:]
+	}
+	names := l.sortedNames()
+	for _, loc := range locales {
+		memory := map[string]string{}
+		conflicts := map[string]bool{}
+		for _, n := range names {
+			s := l.strings[n]
+			v := s.Values[loc]
+			if !l.isTranslatable(s) || !s.IsTranslated(loc) || s.Status[loc] == StatusMemory {
+				continue
+			}
+			key := strings.TrimSpace(s.Values[defLocale])
+			if tr, ok := memory[key]; ok && tr != v {
+				conflicts[key] = true
+			}
+			memory[key] = v
+		}
+		for _, n := range names {
+			s := l.strings[n]
+			key := strings.TrimSpace(s.Values[defLocale])
+			if !l.isTranslatable(s) || s.IsTranslated(loc) || key == "" || conflicts[key] {
+				continue
+			}
+			if tr, ok := memory[key]; ok {
+				s.Values[loc] = tr
+				s.SetStatus(loc, StatusMemory)
+				rep.Filled[loc]++
+			}
+		}
+		for key := range conflicts {
+			rep.Conflicts[loc] = append(rep.Conflicts[loc], key)
+		}
+		sort.Strings(rep.Conflicts[loc])
+	}
+	return rep
+}
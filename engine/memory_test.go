@@ -0,0 +1,52 @@
+package engine
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplyTranslationMemory(t *testing.T) {
+	l := loadProject(t, map[string]string{
+		"values/strings.xml": `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <string name="ok">OK</string>
+    <string name="ok_button">OK </string>
+    <string name="cancel">Cancel</string>
+    <string name="cancel_button">Cancel</string>
+    <string name="retry">Retry</string>
+    <string name="retry_button">Retry</string>
+    <string name="retry_action">Retry</string>
+</resources>
+`,
+		"values-de/strings.xml": `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <string name="ok">Okay</string>
+    <string name="retry">Wiederholen</string>
+    <string name="retry_button">Erneut versuchen</string>
+</resources>
+`})
+	// Save copies default value of cancel to values-de; reloaded copy is not a translation to reuse
+	if err := l.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if l = New(l.ResourcesDir).Load(); l.Err() != nil {
+		t.Fatal(l.Err())
+	}
+	rep := l.ApplyTranslationMemory("de")
+	if rep.Filled["de"] != 1 {
+		t.Errorf("filled %v", rep.Filled)
+	}
+	if !reflect.DeepEqual(rep.Conflicts["de"], []string{"Retry"}) {
+		t.Errorf("conflicts are %v", rep.Conflicts)
+	}
+	s := l.Strings()["ok_button"]
+	if s.Values["de"] != "Okay" || s.Status["de"] != StatusMemory {
+		t.Errorf("ok_button is %q (%s)", s.Values["de"], s.Status["de"])
+	}
+	if v := l.Strings()["cancel_button"].Values["de"]; v != "" {
+		t.Errorf("cancel_button is filled with %q", v)
+	}
+	if v := l.Strings()["retry_action"].Values["de"]; v != "" {
+		t.Errorf("retry_action is filled with %q", v)
+	}
+}
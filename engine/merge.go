@@ -0,0 +1,142 @@
+package engine
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// csvTable is csv file read by columns names
+type csvTable struct {
+	header []string
+	rows   map[string]map[string]string
+	names  []string
+}
+
+//MergeCSV merges csv files (e.g. filled by different translators) into out by ids: columns of all the files
+//are written and every cell gets the value of the file it is not empty in; if files have different non-empty
+//values of the same cell *MergeError describing all the conflicts (sorted by ids and columns) is returned
+//and out is not written
+func (l *Localizer) MergeCSV(out string, inputs ...string) error {
+	if l.err != nil {
+		return l.err
+	}
+	if len(inputs) < 2 {
+		return fmt.Errorf("at least two files are required for merge")
+	}
+	merged := &csvTable{rows: map[string]map[string]string{}}
+	files := map[string]map[string]string{}
+	var conflicts []*MergeConflict
+	for _, fileName := range inputs {
+		t, err := l.readCSVTable(fileName)
+		if err != nil {
+			return &FileError{FileName: fileName, Err: err}
+		}
+		for _, h := range t.header {
+			if !contains(merged.header, h) {
+				merged.header = append(merged.header, h)
+			}
+		}
+		for _, name := range t.names {
+			row, ok := merged.rows[name]
+			if !ok {
+				row = map[string]string{}
+				merged.rows[name] = row
+				files[name] = map[string]string{}
+				merged.names = append(merged.names, name)
+			}
+			for col, v := range t.rows[name] {
+				if strings.TrimSpace(v) == "" {
+					continue
+				}
+				if prev := row[col]; strings.TrimSpace(prev) != "" {
+					if strings.TrimSpace(prev) != strings.TrimSpace(v) {
+						conflicts = append(conflicts, &MergeConflict{Name: name, Column: col,
+							Files: []string{files[name][col], fileName}, Values: []string{prev, v}})
+					}
+					continue
+				}
+				row[col] = v
+				files[name][col] = fileName
+			}
+		}
+	}
+	if len(conflicts) > 0 {
+		// cells are read from maps, so conflicts are sorted to be reported in the same order every time
+		sort.SliceStable(conflicts, func(i, j int) bool {
+			if conflicts[i].Name != conflicts[j].Name {
+				return conflicts[i].Name < conflicts[j].Name
+			}
+			return conflicts[i].Column < conflicts[j].Column
+		})
+		return &MergeError{Conflicts: conflicts}
+	}
+	return l.exportFile(out, func(w io.Writer) error {
+		return merged.write(w, l.delimiter())
+	})
+}
+
+// readCSVTable reads csv file with id column
+func (l *Localizer) readCSVTable(fileName string) (*csvTable, error) {
+	f, err := l.openImportFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	cr := csv.NewReader(f)
+	cr.Comma = l.delimiter()
+	header, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+	t := &csvTable{header: header, rows: map[string]map[string]string{}}
+	for i, h := range header {
+		if contains(header[:i], h) {
+			return nil, fmt.Errorf("%w: column '%s' is given twice", ErrInvalidHeader, h)
+		}
+	}
+	if !contains(header, nameColumn) {
+		return nil, fmt.Errorf("%w: column '%s' is not found", ErrInvalidHeader, nameColumn)
+	}
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return t, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		row := map[string]string{}
+		for i, h := range header {
+			row[h] = rec[i]
+		}
+		name := row[nameColumn]
+		if _, ok := t.rows[name]; ok {
+			line, _ := cr.FieldPos(0)
+			return nil, &RowError{Line: line, Name: name, Err: fmt.Errorf("id '%s' is given twice", name)}
+		}
+		t.rows[name] = row
+		t.names = append(t.names, name)
+	}
+}
+
+func (t *csvTable) write(w io.Writer, comma rune) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	if err := cw.Write(t.header); err != nil {
+		return err
+	}
+	rec := make([]string, len(t.header))
+	for _, name := range t.names {
+		for i, h := range t.header {
+			rec[i] = t.rows[name][h]
+		}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
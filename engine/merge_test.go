@@ -0,0 +1,35 @@
+package engine
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMergeCSVConflictsOrder(t *testing.T) {
+	l := loadProject(t, map[string]string{"values/strings.xml": testDefault})
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.csv")
+	second := filepath.Join(dir, "second.csv")
+	os.WriteFile(first, []byte("id,def,fr,de,it\nhello,Hello,Salut,Hallo,Ciao\nbye,Bye,Au revoir,Tschüss,Ciao\n"), 0644)
+	os.WriteFile(second, []byte("id,def,fr,de,it\nhello,Hello,Bonjour,Servus,Salve\nbye,Bye,Adieu,Servus,Addio\n"), 0644)
+	want := "bye/de bye/fr bye/it hello/de hello/fr hello/it"
+	for i := 0; i < 10; i++ {
+		err := l.MergeCSV(filepath.Join(dir, "out.csv"), first, second)
+		var me *MergeError
+		if !errors.As(err, &me) {
+			t.Fatalf("error is %v", err)
+		}
+		got := ""
+		for _, c := range me.Conflicts {
+			if got != "" {
+				got += " "
+			}
+			got += c.Name + "/" + c.Column
+		}
+		if got != want {
+			t.Fatalf("conflicts are %s", got)
+		}
+	}
+}
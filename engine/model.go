@@ -0,0 +1,66 @@
+package engine
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+//ProjectModel is the whole loaded project with metadata (see Dump)
+type ProjectModel struct {
+	ResourcesDir    string        `json:"resourcesDir"`
+	StringsFileName string        `json:"stringsFile"`
+	Locales         []string      `json:"locales"`
+	Strings         []StringModel `json:"strings"`
+}
+
+//StringModel describes string of project
+type StringModel struct {
+	Name         string `json:"name"`
+	Translatable bool   `json:"translatable"`
+	Comment      string `json:"comment,omitempty"`
+	//Order is index of the string in resource files (strings of default resources file go first)
+	Order int `json:"order"`
+	//Values contains values of all the locales of project
+	Values map[string]ValueModel `json:"values"`
+}
+
+//ValueModel describes value of string for locale
+type ValueModel struct {
+	//Present is false if string has no value for locale (e.g. it is missing in locale resources file)
+	Present bool   `json:"present"`
+	Value   string `json:"value,omitempty"`
+	Status  string `json:"status,omitempty"`
+	Note    string `json:"note,omitempty"`
+}
+
+//Dump returns the whole loaded project (all the strings in source order, including non-translatable
+//and excluded ones) for other tools; unlike exports it reflects which values are present in resources
+func (l *Localizer) Dump() ProjectModel {
+	m := ProjectModel{ResourcesDir: l.ResourcesDir, StringsFileName: l.stringsFileName(), Locales: l.Locales,
+		Strings: []StringModel{}}
+	for _, s := range l.strings {
+		sm := StringModel{Name: s.Name, Translatable: s.Translatable, Comment: s.Comment, Order: s.order,
+			Values: map[string]ValueModel{}}
+		for _, loc := range l.Locales {
+			v, ok := s.Values[loc]
+			sm.Values[loc] = ValueModel{Present: ok, Value: v, Status: s.Status[loc], Note: s.Notes[loc]}
+		}
+		m.Strings = append(m.Strings, sm)
+	}
+	sort.Slice(m.Strings, func(i, j int) bool {
+		return m.Strings[i].Order < m.Strings[j].Order
+	})
+	return m
+}
+
+//DumpJSON writes project model (see Dump) to w as json
+func (l *Localizer) DumpJSON(w io.Writer) error {
+	if l.err != nil {
+		return l.err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", xmlIndent)
+	enc.SetEscapeHTML(false)
+	return enc.Encode(l.Dump())
+}
@@ -0,0 +1,78 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+)
+
+//NewlineError describes translated value with line breaks count that differs from the default value
+type NewlineError struct {
+	Name     string
+	Locale   string
+	Count    int
+	DefCount int
+}
+
+func (e *NewlineError) Error() string {
+	return fmt.Sprintf("%s: value for '%s' has %d line breaks, default has %d", e.Name, e.Locale, e.Count, e.DefCount)
+}
+
+//SetNormalizeNewlines sets whether imports collapse double-escaped line breaks (\\n, entered as text "\n"
+//in translation tools) into \n; real newlines (shown by android as spaces) are always converted to \n
+func (l *Localizer) SetNormalizeNewlines(normalize bool) *Localizer {
+	l.normalizeNewlines = normalize
+	return l
+}
+
+//ValidateNewlines returns *NewlineError for every translated value which has line breaks count different
+//from the default value (usually a layout bug: the line break is lost or entered as text)
+func (l *Localizer) ValidateNewlines() []error {
+	if l.err != nil {
+		return []error{l.err}
+	}
+	var errs []error
+	for _, n := range l.sortedNames() {
+		s := l.strings[n]
+		if !l.isTranslatable(s) {
+			continue
+		}
+		defCount := newlineCount(s.Values[defLocale])
+		for _, loc := range l.Locales[1:] {
+			v, ok := s.Values[loc]
+			if !ok {
+				continue
+			}
+			if count := newlineCount(v); count != defCount {
+				errs = append(errs, &NewlineError{Name: n, Locale: loc, Count: count, DefCount: defCount})
+			}
+		}
+	}
+	return errs
+}
+
+// newlineCount returns count of line breaks shown by android for value
+func newlineCount(value string) int {
+	return strings.Count(PlainText(value), "\n")
+}
+
+// importedNewlines returns imported android value with \\n collapsed into \n if it is enabled
+func (l *Localizer) importedNewlines(value string) string {
+	if !l.normalizeNewlines || !strings.Contains(value, `\\n`) {
+		return value
+	}
+	sb := strings.Builder{}
+	for i := 0; i < len(value); i++ {
+		// escapes are processed by pairs, so \\\n (escaped backslash and line break) is not changed
+		switch {
+		case strings.HasPrefix(value[i:], `\\n`):
+			sb.WriteString(`\n`)
+			i += 2
+		case value[i] == '\\' && i+1 < len(value):
+			sb.WriteString(value[i : i+2])
+			i++
+		default:
+			sb.WriteByte(value[i])
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,54 @@
+package engine
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const testMultilineDefault = `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <string name="multi">Line 1\nLine 2</string>
+</resources>
+`
+
+func TestImportNewlines(t *testing.T) {
+	input := "id,def,de,fr,es\nmulti,,\"Zeile 1\r\nZeile 2\",Ligne 1\\\\nLigne 2,Linea 1 Linea 2\n"
+	tests := []struct {
+		normalize bool
+		want      map[string]string
+	}{
+		{false, map[string]string{"de": `Zeile 1\nZeile 2`, "fr": `Ligne 1\\nLigne 2`}},
+		{true, map[string]string{"de": `Zeile 1\nZeile 2`, "fr": `Ligne 1\nLigne 2`}},
+	}
+	for _, tt := range tests {
+		l := loadProject(t, map[string]string{"values/strings.xml": testMultilineDefault}).SetNormalizeNewlines(tt.normalize)
+		if err := l.ImportR(strings.NewReader(input)); err != nil {
+			t.Fatal(err)
+		}
+		for loc, v := range tt.want {
+			if got := l.Strings()["multi"].Values[loc]; got != v {
+				t.Errorf("normalize %v: %s value is %q instead of %q", tt.normalize, loc, got, v)
+			}
+		}
+	}
+}
+
+func TestValidateNewlines(t *testing.T) {
+	l := loadProject(t, map[string]string{"values/strings.xml": testMultilineDefault})
+	input := "id,def,de,fr,es\nmulti,,Zeile 1\\nZeile 2,Ligne 1\\\\nLigne 2,\"Linea 1\\nLinea 2\\n\"\n"
+	if err := l.ImportR(strings.NewReader(input)); err != nil {
+		t.Fatal(err)
+	}
+	errs := l.ValidateNewlines()
+	want := map[string][2]int{"fr": {0, 1}, "es": {2, 1}}
+	if len(errs) != len(want) {
+		t.Fatalf("errors are %v", errs)
+	}
+	for _, err := range errs {
+		var ne *NewlineError
+		if !errors.As(err, &ne) || ne.Name != "multi" || want[ne.Locale] != [2]int{ne.Count, ne.DefCount} {
+			t.Errorf("unexpected error %v", err)
+		}
+	}
+}
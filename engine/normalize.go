@@ -0,0 +1,215 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var startTagSpaceRegexp = regexp.MustCompile(`\s+`)
+
+//NormalizeOptions are options of rewriting of default resources file by SaveDefault
+type NormalizeOptions struct {
+	//Order is order of elements: OrderAlpha sorts them by names, OrderGroup groups them (see SetRowGroups)
+	//separating groups with empty lines, OrderSource keeps the order of file
+	Order RowOrder
+	//Indent is indentation of elements (indentation set by SetIndent or the file's own one if empty)
+	Indent string
+	//Diff receives line diff of the file instead of writing it (dry run) if it is not nil
+	Diff io.Writer
+}
+
+// resourceEntry is top level element of resources file with comments preceding it
+type resourceEntry struct {
+	name     string
+	comments [][]byte
+	element  []byte
+}
+
+//SaveDefault rewrites default resources file (values/strings.xml) normalizing its style: elements are ordered
+//(see NormalizeOptions), indented consistently, attributes are separated by single spaces and values of strings
+//get escaped ampersands and apostrophes; comments go with elements they precede, other elements (plurals,
+//arrays) are kept as they are. Values of strings are taken from engine (e.g. with normalized whitespace, see
+//SetNormalizeWhitespace). Nothing is written if the file would not change
+func (l *Localizer) SaveDefault(opts NormalizeOptions) error {
+	if l.err != nil {
+		return l.err
+	}
+	if opts.Diff == nil {
+		if _, err := l.writeFS(); err != nil {
+			return err
+		}
+	}
+	fileName := l.getFileNameForLocale(defLocale)
+	content, err := l.readFile(fileName)
+	if err != nil {
+		return err
+	}
+	normalized, err := l.normalizeDefault(content, opts)
+	if err != nil {
+		return &FileError{FileName: fileName, Err: err}
+	}
+	if opts.Diff != nil {
+		return writeDiff(opts.Diff, fileName, content, normalized)
+	}
+	return l.writeContent(fileName, normalized)
+}
+
+// normalizeDefault returns content of default resources file rewritten according to opts
+func (l *Localizer) normalizeDefault(content []byte, opts NormalizeOptions) ([]byte, error) {
+	indent := opts.Indent
+	if indent == "" {
+		indent = l.indent
+	}
+	if indent == "" {
+		indent = detectIndent(content)
+	}
+	if indent == "" {
+		indent = xmlIndent
+	}
+	content, err := utf8Content(content)
+	if err != nil {
+		return nil, err
+	}
+	d := xml.NewDecoder(bytes.NewReader(content))
+	var entries []resourceEntry
+	var comments [][]byte
+	var prolog, root, epilog []byte
+	depth := 0
+	for epilog == nil {
+		start := d.InputOffset()
+		t, err := d.Token()
+		if err == io.EOF {
+			return nil, fmt.Errorf("closing </resources> not found")
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := t.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				prolog, root = content[:start], content[start:d.InputOffset()]
+				depth++
+				continue
+			}
+			tagEnd := d.InputOffset()
+			if err = d.Skip(); err != nil {
+				return nil, err
+			}
+			e := resourceEntry{name: attrValue(t, "name"), comments: comments,
+				element: reindent(content[start:d.InputOffset()], lineIndent(content[:start]), indent)}
+			if t.Name.Local == "string" {
+				e.element = l.normalizeString(e.name, content[start:tagEnd], content[tagEnd:d.InputOffset()])
+			}
+			entries = append(entries, e)
+			comments = nil
+		case xml.Comment:
+			if depth == 1 {
+				comments = append(comments, reindent(content[start:d.InputOffset()], lineIndent(content[:start]), indent))
+			}
+		case xml.EndElement:
+			epilog = content[start:]
+		}
+	}
+	l.orderEntries(entries, opts.Order)
+	out := bytes.Buffer{}
+	out.Write(prolog)
+	out.Write(startTagSpaceRegexp.ReplaceAll(root, []byte(" ")))
+	out.WriteString("\n")
+	for i, e := range entries {
+		if opts.Order == OrderGroup && i > 0 && l.rowGroupOf(e.name) != l.rowGroupOf(entries[i-1].name) {
+			out.WriteString("\n")
+		}
+		for _, c := range e.comments {
+			fmt.Fprintf(&out, "%s%s\n", indent, c)
+		}
+		fmt.Fprintf(&out, "%s%s\n", indent, e.element)
+	}
+	for _, c := range comments {
+		fmt.Fprintf(&out, "%s%s\n", indent, c)
+	}
+	out.Write(epilog)
+	return out.Bytes(), nil
+}
+
+// orderEntries sorts entries by order keeping source order of entries with the same rank
+func (l *Localizer) orderEntries(entries []resourceEntry, order RowOrder) {
+	switch order {
+	case OrderAlpha:
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].name < entries[j].name
+		})
+	case OrderGroup:
+		names := make([]string, len(entries))
+		for i, e := range entries {
+			names[i] = e.name
+		}
+		rank := l.groupRanks(names)
+		sort.SliceStable(entries, func(i, j int) bool {
+			return rank[l.rowGroupOf(entries[i].name)] < rank[l.rowGroupOf(entries[j].name)]
+		})
+	}
+}
+
+// normalizeString returns string element with normalized start tag and escaping of value
+func (l *Localizer) normalizeString(name string, tag, content []byte) []byte {
+	tag = startTagSpaceRegexp.ReplaceAll(tag, []byte(" "))
+	tag = bytes.Replace(bytes.Replace(tag, []byte(" >"), []byte(">"), 1), []byte(" />"), []byte("/>"), 1)
+	if bytes.HasSuffix(tag, []byte("/>")) {
+		return tag
+	}
+	value := string(content[:bytes.LastIndex(content, []byte("</"))])
+	if s, ok := l.strings[name]; ok {
+		value = s.Values[defLocale]
+	}
+	return []byte(string(tag) + normalizeEscaping(value) + "</string>")
+}
+
+// normalizeEscaping escapes bare ampersands and apostrophes of value (quoted values and CDATA sections are kept)
+func normalizeEscaping(value string) string {
+	if strings.HasPrefix(strings.TrimSpace(value), `"`) || strings.Contains(value, "<![CDATA[") {
+		return escapeAmpersands(value)
+	}
+	sb := strings.Builder{}
+	inTag, escaped := false, false
+	for _, r := range escapeAmpersands(value) {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\' && !inTag:
+			escaped = true
+		case r == '<':
+			inTag = true
+		case r == '>':
+			inTag = false
+		case r == '\'' && !inTag:
+			sb.WriteRune('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// reindent replaces indentation of lines of element after the first one
+func reindent(element, from []byte, to string) []byte {
+	lines := bytes.Split(element, []byte("\n"))
+	for i := 1; i < len(lines); i++ {
+		if bytes.HasPrefix(lines[i], from) {
+			lines[i] = append([]byte(to), lines[i][len(from):]...)
+		}
+	}
+	return bytes.Join(lines, []byte("\n"))
+}
+
+func attrValue(t xml.StartElement, name string) string {
+	for _, a := range t.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,28 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSaveDefaultKeepsIndent(t *testing.T) {
+	content := `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <string name="bye">Bye</string>
+    <string name="hello">Hello</string>
+</resources>
+`
+	l := loadProject(t, map[string]string{"values/strings.xml": content})
+	if err := l.SaveDefault(NormalizeOptions{Order: OrderAlpha}); err != nil {
+		t.Fatal(err)
+	}
+	if got := readProjectFile(t, l, "values/strings.xml"); got != content {
+		t.Errorf("normalized file is\n%s", got)
+	}
+	if err := l.SaveDefault(NormalizeOptions{Order: OrderAlpha, Indent: "\t"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := readProjectFile(t, l, "values/strings.xml"); !strings.Contains(got, "\n\t<string name=\"bye\">") {
+		t.Errorf("file is not reindented:\n%s", got)
+	}
+}
@@ -0,0 +1,39 @@
+package engine
+
+import "strings"
+
+// notesSeparator separates different notes of locales in csv note column
+const notesSeparator = "; "
+
+//SetNote sets reviewer's note for value of locale (empty note removes it)
+func (s *String) SetNote(loc, note string) {
+	if note == "" {
+		delete(s.Notes, loc)
+		return
+	}
+	if s.Notes == nil {
+		s.Notes = map[string]string{}
+	}
+	s.Notes[loc] = note
+}
+
+// note returns distinct notes of given locales joined for csv note column
+func (s *String) note(locales []string) string {
+	var notes []string
+	for _, loc := range locales {
+		if n := s.Notes[loc]; n != "" && !contains(notes, n) {
+			notes = append(notes, n)
+		}
+	}
+	return strings.Join(notes, notesSeparator)
+}
+
+// hasNotes returns true if any of strings has note for any of locales
+func (l *Localizer) hasNotes(locales []string) bool {
+	for _, s := range l.strings {
+		if s.note(locales) != "" {
+			return true
+		}
+	}
+	return false
+}
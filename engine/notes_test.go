@@ -0,0 +1,40 @@
+package engine
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestXMLComment(t *testing.T) {
+	for text, comment := range map[string]string{
+		"needs legal review": "<!-- needs legal review -->",
+		"see --- legal-":     "<!-- see - - - legal- -->",
+		"a----b":             "<!-- a- - - -b -->",
+		"-":                  "<!-- - -->",
+	} {
+		got := xmlComment(text)
+		if got != comment {
+			t.Errorf("comment of %q is %q instead of %q", text, got, comment)
+		}
+		d := xml.NewDecoder(strings.NewReader(got))
+		if _, err := d.Token(); err != nil {
+			t.Errorf("%q is not valid xml: %v", got, err)
+		}
+	}
+}
+
+func TestNotesSurviveSave(t *testing.T) {
+	l := loadProject(t, map[string]string{"values/strings.xml": testDefault, "values-de/strings.xml": testGerman})
+	l.Strings()["hello"].SetNote("de", "see --- legal-")
+	if err := l.Save(); err != nil {
+		t.Fatal(err)
+	}
+	l = New(l.ResourcesDir).Load()
+	if err := l.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if note := l.Strings()["hello"].Notes["de"]; note != "see - - - legal-" {
+		t.Errorf("note is %q", note)
+	}
+}
@@ -0,0 +1,63 @@
+package engine
+
+import (
+	"io/fs"
+	"strings"
+)
+
+//Option sets up engine created by NewE
+type Option func(l *Localizer)
+
+//WithLocales sets required locales (see New); project config locales are used if none given
+func WithLocales(locales ...string) Option {
+	return func(l *Localizer) {
+		if len(locales) > 0 {
+			l.requestedLocales = locales
+		}
+	}
+}
+
+//WithStringsFileName sets name of resource files in values dirs (see SetStringsFileName)
+func WithStringsFileName(name string) Option {
+	return func(l *Localizer) {
+		l.StringsFileName = name
+	}
+}
+
+//WithModule sets gradle module resources of which are processed (see SetModule)
+func WithModule(module string) Option {
+	return func(l *Localizer) {
+		l.module = strings.TrimPrefix(module, ":")
+	}
+}
+
+//WithCanonicalLocales keeps locales in canonical order (see SetCanonicalLocales)
+func WithCanonicalLocales() Option {
+	return func(l *Localizer) {
+		l.canonicalLocales = true
+	}
+}
+
+//WithFS sets filesystem project is read from (see NewFromFS)
+func WithFS(fsys fs.FS) Option {
+	return func(l *Localizer) {
+		l.fsys = fsys
+	}
+}
+
+//NewE creates new localization engine like New but returns error (e.g. resources dir is not found)
+//instead of keeping it for the following calls; options override defaults of project config file
+func NewE(projectDir string, opts ...Option) (*Localizer, error) {
+	l := &Localizer{fsys: osFS{}, projectDir: projectDir}
+	for _, opt := range opts {
+		opt(l)
+	}
+	l.loadConfig()
+	l.init()
+	return l, l.err
+}
+
+//LoadE loads resources like Load and returns error instead of keeping it for the following calls
+func (l *Localizer) LoadE() error {
+	return l.Load().err
+}
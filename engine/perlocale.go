@@ -0,0 +1,116 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const csvExt = ".csv"
+
+//SetOnlyMissing sets whether ExportPerLocale writes only strings missing in the file's locale
+func (l *Localizer) SetOnlyMissing(only bool) *Localizer {
+	l.onlyMissing = only
+	return l
+}
+
+//ExportPerLocale writes <locale>.csv file (e.g. de.csv, pt-rBR.csv) with id, def and locale columns
+//to dir for every non-default locale (e.g. for vendors working with single language);
+//files may be imported as usual
+func (l *Localizer) ExportPerLocale(dir string) error {
+	if l.err != nil {
+		return l.err
+	}
+	err := l.mkdirAllOf(dir)
+	if err != nil {
+		return err
+	}
+	for _, loc := range l.Locales[1:] {
+		var filter func(s *String) bool
+		if l.onlyMissing {
+			filter = func(s *String) bool {
+				return !s.IsTranslated(loc)
+			}
+		}
+		err = l.createFile(filepath.Join(dir, loc+csvExt), func(w io.Writer) error {
+			return l.writeCSVFiltered(w, l.delimiter(), []string{loc}, false, filter)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//ImportDir imports values from every *.csv file in dir (e.g. returned files of ExportPerLocale);
+//locale of file with single locale column that is not named by locale is taken from file name (de.csv).
+//Files are imported independently: invalid one does not prevent others from being imported;
+//*DirImportError is returned if there were failed files (see LastDirImport for results of all the files)
+func (l *Localizer) ImportDir(dir string) error {
+	if l.err != nil {
+		return l.err
+	}
+	files, err := l.glob(filepath.Join(dir, "*"+csvExt))
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+	results := []*FileImportResult{}
+	failed := 0
+	for _, fileName := range files {
+		res := &FileImportResult{FileName: fileName}
+		l.lastImport = nil
+		res.Err = l.importCSVFile(fileName)
+		res.Summary = l.lastImport
+		l.lastImport = nil
+		if res.Err != nil {
+			failed++
+		}
+		results = append(results, res)
+	}
+	l.lastDirImport = results
+	if failed > 0 {
+		return &DirImportError{Results: results, Failed: failed}
+	}
+	return nil
+}
+
+//LastDirImport returns results of files of the last ImportDir
+func (l *Localizer) LastDirImport() []*FileImportResult {
+	return l.lastDirImport
+}
+
+// importCSVFile imports csv file naming its only locale column by file name if needed
+func (l *Localizer) importCSVFile(fileName string) error {
+	content, err := l.readFileOf(fileName)
+	if err != nil {
+		return err
+	}
+	cr := csv.NewReader(bytes.NewReader(content))
+	cr.Comma = l.delimiter()
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return err
+	}
+	if len(header) == 3 {
+		if _, ok := l.columnLocale(header[2]); !ok {
+			loc, err := NormalizeLocale(strings.TrimSuffix(filepath.Base(fileName), csvExt))
+			if err != nil {
+				return fmt.Errorf("can not guess locale of column '%s': %w", header[2], err)
+			}
+			header[2] = loc
+			buf := &bytes.Buffer{}
+			cw := csv.NewWriter(buf)
+			cw.Comma = l.delimiter()
+			cw.Write(header)
+			cw.Flush()
+			content = append(buf.Bytes(), content[cr.InputOffset():]...)
+		}
+	}
+	return l.readCSV(bytes.NewReader(content), l.delimiter(), l.importLocales)
+}
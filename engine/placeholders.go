@@ -0,0 +1,398 @@
+package engine
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	placeholderRegexp = regexp.MustCompile(`%(?:(\d+)\$)?([-#+ 0,(<]*)(\d+)?(?:\.(\d+))?([bcdeEfgGhHosSxXn%]|[tT][a-zA-Z])`)
+	xliffRegexp       = regexp.MustCompile(`(?s)<xliff:g\b([^>]*)>.*?</xliff:g>`)
+	xliffAttrRegexp   = regexp.MustCompile(`\b(id|example)\s*=\s*"([^"]*)"`)
+)
+
+// argumentsColumn is csv column with placeholders of default value (see SetExportArguments)
+const argumentsColumn = "args"
+
+//Placeholder describes format argument found in value (e.g. %1$s)
+type Placeholder struct {
+	//Index is 1-based argument index; for non-positional placeholders it's the order of placeholder
+	Index int
+	//Positional is true if index is given explicitly (%2$s)
+	Positional bool
+	//Flags contains flags, width and precision (e.g. ".2" for %.2f)
+	Flags string
+	//Conversion is conversion character (s, d, f...)
+	Conversion string
+	//Text is placeholder as it is written in value
+	Text string
+	//ID and Example are attributes of xliff:g element placeholder is enclosed in
+	ID      string
+	Example string
+}
+
+//String returns placeholder in normalized form (%<index>$<conversion>)
+func (p Placeholder) String() string {
+	return fmt.Sprintf("%%%d$%s", p.Index, p.Conversion)
+}
+
+//WithIndex returns placeholder with explicit index (%<index>$<flags><conversion>)
+func (p Placeholder) WithIndex() string {
+	return fmt.Sprintf("%%%d$%s%s", p.Index, p.Flags, p.Conversion)
+}
+
+//Type returns kind of argument by conversion: string, number, char, boolean, date or any
+func (p Placeholder) Type() string {
+	conv := strings.ToLower(p.Conversion)
+	if strings.HasPrefix(conv, "t") {
+		// date/time conversion has suffix (e.g. %tY)
+		conv = "t"
+	}
+	switch conv {
+	case "s":
+		return "string"
+	case "d", "o", "x", "f", "e", "g", "a":
+		return "number"
+	case "c":
+		return "char"
+	case "b":
+		return "boolean"
+	case "t":
+		return "date"
+	}
+	return "any"
+}
+
+//ParsePlaceholders returns format arguments of value in order of appearance (with id and example
+//of xliff:g elements they are enclosed in); escaped percent (%%) and line separator (%n) are skipped
+func ParsePlaceholders(value string) []Placeholder {
+	res := []Placeholder{}
+	next := 1
+	xliffs := xliffRegexp.FindAllStringSubmatchIndex(value, -1)
+	for _, loc := range placeholderIndexes(value) {
+		m := submatches(value, loc)
+		conv := m[5]
+		if conv == "%" || conv == "n" {
+			continue
+		}
+		p := Placeholder{Conversion: conv, Text: m[0], Flags: m[2] + m[3]}
+		if m[4] != "" {
+			p.Flags += "." + m[4]
+		}
+		if m[1] != "" {
+			p.Index, _ = strconv.Atoi(m[1])
+			p.Positional = true
+		} else {
+			p.Index = next
+			next++
+		}
+		for _, x := range xliffs {
+			if loc[0] >= x[0] && loc[1] <= x[1] {
+				for _, a := range xliffAttrRegexp.FindAllStringSubmatch(value[x[2]:x[3]], -1) {
+					if a[1] == "id" {
+						p.ID = a[2]
+					} else {
+						p.Example = a[2]
+					}
+				}
+			}
+		}
+		res = append(res, p)
+	}
+	return res
+}
+
+// placeholderIndexes returns submatch indexes of placeholders of value (see placeholderRegexp); match with
+// space flag followed by letter is prose (e.g. "50% off"), not a placeholder
+func placeholderIndexes(value string) [][]int {
+	res := [][]int{}
+	for _, loc := range placeholderRegexp.FindAllStringSubmatchIndex(value, -1) {
+		if strings.Contains(value[loc[4]:loc[5]], " ") && loc[1] < len(value) && isASCIILetter(value[loc[1]]) {
+			continue
+		}
+		res = append(res, loc)
+	}
+	return res
+}
+
+func isASCIILetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// submatches returns submatches of s by their indexes (see regexp.FindStringSubmatchIndex)
+func submatches(s string, loc []int) []string {
+	res := make([]string, len(loc)/2)
+	for i := range res {
+		if loc[2*i] >= 0 {
+			res[i] = s[loc[2*i]:loc[2*i+1]]
+		}
+	}
+	return res
+}
+
+//Placeholders returns format arguments of default value (see ParsePlaceholders)
+func (s *String) Placeholders() []Placeholder {
+	return ParsePlaceholders(s.Values[defLocale])
+}
+
+//SetExportArguments sets whether csv exports have column 'args' describing placeholders of default values
+//for translators (e.g. %1$s(string, user), %2$d(number)); the column is ignored on import
+func (l *Localizer) SetExportArguments(export bool) *Localizer {
+	l.exportArguments = export
+	return l
+}
+
+// arguments describes placeholders of default value of string for translators
+func arguments(s *String) string {
+	var args []string
+	for _, p := range s.Placeholders() {
+		descr := p.Type()
+		if p.ID != "" {
+			descr += ", " + p.ID
+		}
+		if p.Example != "" {
+			descr += ", e.g. " + p.Example
+		}
+		args = append(args, fmt.Sprintf("%s(%s)", p.Text, descr))
+	}
+	return strings.Join(args, ", ")
+}
+
+//PlaceholderError describes difference of placeholders of translated value and default value
+type PlaceholderError struct {
+	Name     string
+	Locale   string
+	Expected []string
+	Found    []string
+}
+
+func (e *PlaceholderError) Error() string {
+	return fmt.Sprintf("%s: placeholders for '%s' are [%s], default has [%s]", e.Name, e.Locale, strings.Join(e.Found, " "), strings.Join(e.Expected, " "))
+}
+
+//PlaceholderTypeError describes argument that has conversion of other type in translated value
+//than in default value (e.g. %1$d instead of %1$s), which makes formatting fail at runtime
+type PlaceholderTypeError struct {
+	Name     string
+	Locale   string
+	Index    int
+	Expected Placeholder
+	Found    Placeholder
+}
+
+func (e *PlaceholderTypeError) Error() string {
+	return fmt.Sprintf("%s: arg %d is %%%s in def but %%%s in %s", e.Name, e.Index, e.Expected.Conversion, e.Found.Conversion, e.Locale)
+}
+
+//ValidatePlaceholders compares format arguments of translated values with the default ones
+//and returns *PlaceholderTypeError for every argument with conversion of other type (e.g. swapped
+//arguments of different types) and *PlaceholderError for every other mismatch
+func (l *Localizer) ValidatePlaceholders() []error {
+	if l.err != nil {
+		return []error{l.err}
+	}
+	var errs []error
+	for _, n := range l.sortedNames() {
+		s := l.strings[n]
+		if !l.isTranslatable(s) {
+			continue
+		}
+		def := ParsePlaceholders(s.Values[defLocale])
+		expected := normalizedPlaceholders(s.Values[defLocale])
+		for _, loc := range l.Locales[1:] {
+			v := s.Values[loc]
+			if v == "" {
+				continue
+			}
+			phs := ParsePlaceholders(v)
+			typeErrs := placeholderTypeErrors(n, loc, def, phs)
+			errs = append(errs, typeErrs...)
+			found := normalizedPlaceholders(v)
+			// differences explained by type errors are not reported again
+			if strings.Join(found, " ") != strings.Join(expected, " ") &&
+				(len(typeErrs) == 0 || !sameIndexes(def, phs)) {
+				errs = append(errs, &PlaceholderError{Name: n, Locale: loc, Expected: expected, Found: found})
+			}
+		}
+	}
+	return errs
+}
+
+// placeholderTypeErrors returns *PlaceholderTypeError for every index of found placeholders
+// which conversion type differs from the one of default placeholder with the same index
+func placeholderTypeErrors(name, loc string, def, found []Placeholder) []error {
+	byIndex := map[int]Placeholder{}
+	for _, p := range def {
+		if _, ok := byIndex[p.Index]; !ok {
+			byIndex[p.Index] = p
+		}
+	}
+	var errs []error
+	reported := map[int]bool{}
+	for _, p := range found {
+		d, ok := byIndex[p.Index]
+		if ok && !reported[p.Index] && conversionClass(d.Conversion) != conversionClass(p.Conversion) {
+			errs = append(errs, &PlaceholderTypeError{Name: name, Locale: loc, Index: p.Index, Expected: d, Found: p})
+			reported[p.Index] = true
+		}
+	}
+	return errs
+}
+
+// conversionClass returns class of arguments accepted by conversion: integer conversions (%d, %x, %o)
+// are compatible with each other, as are floating point ones and upper and lower case forms
+func conversionClass(conv string) string {
+	switch strings.ToLower(conv) {
+	case "d", "o", "x":
+		return "integer"
+	case "f", "e", "g", "a":
+		return "float"
+	}
+	return strings.ToLower(conv)
+}
+
+// sameIndexes returns true if a and b refer to the same set of argument indexes
+func sameIndexes(a, b []Placeholder) bool {
+	set := func(phs []Placeholder) string {
+		idx := map[int]bool{}
+		for _, p := range phs {
+			idx[p.Index] = true
+		}
+		var res []int
+		for i := range idx {
+			res = append(res, i)
+		}
+		sort.Ints(res)
+		return fmt.Sprint(res)
+	}
+	return set(a) == set(b)
+}
+
+func normalizedPlaceholders(value string) []string {
+	res := []string{}
+	for _, p := range ParsePlaceholders(value) {
+		res = append(res, p.String())
+	}
+	sort.Strings(res)
+	return res
+}
+
+var namedPlaceholderRegexp = regexp.MustCompile(`\{arg(\d+)\}`)
+
+// toNamedPlaceholders replaces printf placeholders of plain text with {argN} ones (%% becomes %)
+// and returns placeholders found
+func toNamedPlaceholders(text string) (string, []Placeholder) {
+	return replaceWithNamed(text, "\n")
+}
+
+// printfToNamed replaces printf placeholders of android value with {argN} ones (%% becomes %, %n becomes \n)
+func printfToNamed(value string) string {
+	res, _ := replaceWithNamed(value, `\n`)
+	return res
+}
+
+func replaceWithNamed(text string, newline string) (string, []Placeholder) {
+	phs := ParsePlaceholders(text)
+	res := &strings.Builder{}
+	i, last := 0, 0
+	for _, loc := range placeholderIndexes(text) {
+		res.WriteString(text[last:loc[0]])
+		last = loc[1]
+		switch text[loc[10]:loc[11]] {
+		case "%":
+			res.WriteString("%")
+		case "n":
+			res.WriteString(newline)
+		default:
+			fmt.Fprintf(res, "{arg%d}", phs[i].Index)
+			i++
+		}
+	}
+	res.WriteString(text[last:])
+	return res.String(), phs
+}
+
+// fromNamedPlaceholders replaces {argN} placeholders of value with printf ones of default value
+// (or with %N$s if default has no such placeholder); if there are any placeholders, % is escaped
+func fromNamedPlaceholders(value string, def []Placeholder) string {
+	if !namedPlaceholderRegexp.MatchString(value) {
+		return value
+	}
+	value = strings.Replace(value, "%", "%%", -1)
+	return namedPlaceholderRegexp.ReplaceAllStringFunc(value, func(m string) string {
+		idx, _ := strconv.Atoi(namedPlaceholderRegexp.FindStringSubmatch(m)[1])
+		for _, p := range def {
+			if p.Index == idx {
+				return p.WithIndex()
+			}
+		}
+		return fmt.Sprintf("%%%d$s", idx)
+	})
+}
+
+//PlaceholderStyle is style of placeholders in values
+type PlaceholderStyle int
+
+const (
+	//PrintfPlaceholders is android style: %1$s, %2$.2f, %d
+	PrintfPlaceholders PlaceholderStyle = iota
+	//NamedPlaceholders is named-brace style: {arg1}, {arg2}
+	NamedPlaceholders
+)
+
+//ParsePlaceholderStyle returns PlaceholderStyle by its name: printf or named
+func ParsePlaceholderStyle(name string) (PlaceholderStyle, error) {
+	switch name {
+	case "printf", "":
+		return PrintfPlaceholders, nil
+	case "named":
+		return NamedPlaceholders, nil
+	}
+	return PrintfPlaceholders, fmt.Errorf("invalid placeholder style '%s': should be printf or named", name)
+}
+
+//ConvertPlaceholders rewrites values of all the strings in all the locales from one placeholder style to another.
+//Placeholder with argument index N (%N$s or N-th of non-positional ones) becomes {argN}, %% becomes % and %n becomes \n;
+//{argN} becomes placeholder with index N of default value before conversion to named style (with its flags
+//and conversion, e.g. %2$.2f) or %N$s if there is no such placeholder, % is escaped as %% if there are placeholders
+func (l *Localizer) ConvertPlaceholders(from, to PlaceholderStyle) error {
+	if l.err != nil {
+		return l.err
+	}
+	if from == to {
+		return nil
+	}
+	if l.printfPlaceholders == nil {
+		l.printfPlaceholders = map[string][]Placeholder{}
+	}
+	for n, s := range l.strings {
+		switch to {
+		case NamedPlaceholders:
+			l.printfPlaceholders[n] = ParsePlaceholders(s.Values[defLocale])
+			for loc, v := range s.Values {
+				s.Values[loc] = printfToNamed(v)
+			}
+		case PrintfPlaceholders:
+			def, ok := l.printfPlaceholders[n]
+			if !ok {
+				def = ParsePlaceholders(s.Values[defLocale])
+			}
+			for loc, v := range s.Values {
+				s.Values[loc] = fromNamedPlaceholders(v, def)
+			}
+		default:
+			return fmt.Errorf("unknown placeholder style %d", to)
+		}
+	}
+	return nil
+}
+
+//SetJSONPlaceholderStyle sets style of placeholders in json and ndjson exports and imports
+//(values are converted as by ConvertPlaceholders)
+func (l *Localizer) SetJSONPlaceholderStyle(style PlaceholderStyle) *Localizer {
+	l.jsonPlaceholders = style
+	return l
+}
@@ -0,0 +1,203 @@
+package engine
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const testPlaceholdersDefault = `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <string name="greeting">Hi %1$s, you have %2$d messages (%3$.1f%%)</string>
+    <string name="plain">%s sent %d files</string>
+</resources>
+`
+
+const testPlaceholdersFrench = `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <string name="greeting">%2$d messages pour %1$s (%3$.1f%%)</string>
+    <string name="plain">%s a envoyé %d fichiers</string>
+</resources>
+`
+
+func TestConvertPlaceholders(t *testing.T) {
+	l := loadProject(t, map[string]string{
+		"values/strings.xml":    testPlaceholdersDefault,
+		"values-fr/strings.xml": testPlaceholdersFrench,
+	})
+	if err := l.ConvertPlaceholders(PrintfPlaceholders, NamedPlaceholders); err != nil {
+		t.Fatal(err)
+	}
+	named := map[string]map[string]string{
+		"greeting": {defLocale: "Hi {arg1}, you have {arg2} messages ({arg3}%)", "fr": "{arg2} messages pour {arg1} ({arg3}%)"},
+		"plain":    {defLocale: "{arg1} sent {arg2} files", "fr": "{arg1} a envoyé {arg2} fichiers"},
+	}
+	for n, values := range named {
+		for loc, v := range values {
+			if got := l.Strings()[n].Values[loc]; got != v {
+				t.Errorf("%s/%s: named value is %q instead of %q", n, loc, got, v)
+			}
+		}
+	}
+	if err := l.ConvertPlaceholders(NamedPlaceholders, PrintfPlaceholders); err != nil {
+		t.Fatal(err)
+	}
+	printf := map[string]map[string]string{
+		"greeting": {defLocale: "Hi %1$s, you have %2$d messages (%3$.1f%%)", "fr": "%2$d messages pour %1$s (%3$.1f%%)"},
+		// non-positional placeholders get their indexes
+		"plain": {defLocale: "%1$s sent %2$d files", "fr": "%1$s a envoyé %2$d fichiers"},
+	}
+	for n, values := range printf {
+		for loc, v := range values {
+			if got := l.Strings()[n].Values[loc]; got != v {
+				t.Errorf("%s/%s: value is converted back to %q instead of %q", n, loc, got, v)
+			}
+		}
+	}
+}
+
+func TestJSONNamedPlaceholders(t *testing.T) {
+	l := loadProject(t, map[string]string{
+		"values/strings.xml":    testPlaceholdersDefault,
+		"values-fr/strings.xml": testPlaceholdersFrench,
+	}).SetJSONPlaceholderStyle(NamedPlaceholders)
+	buf := &bytes.Buffer{}
+	if err := l.ExportJSONW(buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"{arg2} messages pour {arg1} ({arg3}%)"`) {
+		t.Fatalf("json has no named placeholders:\n%s", buf)
+	}
+	input := strings.Replace(buf.String(), "{arg2} messages pour {arg1}", "{arg1} a {arg2} messages", 1)
+	if err := l.ImportJSONR(strings.NewReader(input)); err != nil {
+		t.Fatal(err)
+	}
+	if got := l.Strings()["greeting"].Values["fr"]; got != "%1$s a %2$d messages (%3$.1f%%)" {
+		t.Errorf("imported value is %q", got)
+	}
+	if got := l.Strings()["plain"].Values["fr"]; got != "%s a envoyé %d fichiers" {
+		t.Errorf("unchanged value is imported as %q", got)
+	}
+}
+
+func TestParsePlaceholders(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []Placeholder
+	}{
+		{"no args", []Placeholder{}},
+		{"%1$s has %2$d", []Placeholder{
+			{Index: 1, Positional: true, Conversion: "s", Text: "%1$s"},
+			{Index: 2, Positional: true, Conversion: "d", Text: "%2$d"},
+		}},
+		{"%s has %.2f", []Placeholder{
+			{Index: 1, Conversion: "s", Text: "%s"},
+			{Index: 2, Conversion: "f", Text: "%.2f", Flags: ".2"},
+		}},
+		{"100%% of %d%n", []Placeholder{
+			{Index: 1, Conversion: "d", Text: "%d"},
+		}},
+		{"%%s is not an argument", []Placeholder{}},
+		{"%2$-5s before %1$05d", []Placeholder{
+			{Index: 2, Positional: true, Conversion: "s", Text: "%2$-5s", Flags: "-5"},
+			{Index: 1, Positional: true, Conversion: "d", Text: "%1$05d", Flags: "05"},
+		}},
+		// percent sign in prose is not a placeholder
+		{"50% off", []Placeholder{}},
+		{"20 % Rabatt", []Placeholder{}},
+		{"% d items, 5%.", []Placeholder{
+			{Index: 1, Conversion: "d", Text: "% d", Flags: " "},
+		}},
+		{"%1$tY-%1$tm", []Placeholder{
+			{Index: 1, Positional: true, Conversion: "tY", Text: "%1$tY"},
+			{Index: 1, Positional: true, Conversion: "tm", Text: "%1$tm"},
+		}},
+		{`Hi <xliff:g id="user" example="Bob">%1$s</xliff:g>`, []Placeholder{
+			{Index: 1, Positional: true, Conversion: "s", Text: "%1$s", ID: "user", Example: "Bob"},
+		}},
+	}
+	for _, tt := range tests {
+		got := ParsePlaceholders(tt.value)
+		if len(got) != len(tt.want) {
+			t.Errorf("ParsePlaceholders(%q) = %+v, want %+v", tt.value, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("ParsePlaceholders(%q)[%d] = %+v, want %+v", tt.value, i, got[i], tt.want[i])
+			}
+		}
+	}
+	if typ := ParsePlaceholders("%1$tY")[0].Type(); typ != "date" {
+		t.Errorf("type of %%1$tY is %s", typ)
+	}
+	if v := printfToNamed("50% off %s, 20 % Rabatt%n"); v != `50% off {arg1}, 20 % Rabatt\n` {
+		t.Errorf("named value is %q", v)
+	}
+}
+
+func TestArgumentsColumn(t *testing.T) {
+	def := strings.Replace(testDefault, ">Hello<", `>Hello <xliff:g id="user" example="Bob">%1$s</xliff:g>, %2$d new<`, 1)
+	l := loadProject(t, map[string]string{"values/strings.xml": def}, "de").SetExportArguments(true)
+	buf := &bytes.Buffer{}
+	if err := l.ExportW(buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"%1$s(string, user, e.g. Bob), %2$d(number)"`) {
+		t.Errorf("export has no arguments:\n%s", buf)
+	}
+	input := "id,def,args,de\nhello,,%1$s(string),Hallo %1$s\n"
+	if err := l.SetStrict(true).ImportR(strings.NewReader(input)); err != nil {
+		t.Fatal(err)
+	}
+	if got := l.Strings()["hello"].Values["de"]; got != "Hallo %1$s" {
+		t.Errorf("imported value is %q", got)
+	}
+	if contains(l.Locales, argumentsColumn) {
+		t.Errorf("arguments column is imported as locale: %v", l.Locales)
+	}
+}
+
+func TestValidatePlaceholderTypes(t *testing.T) {
+	def := `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <string name="greeting">Hi %1$s, you have %2$d messages</string>
+    <string name="price">%1$.2f for %2$s</string>
+    <string name="count">%d items</string>
+</resources>
+`
+	fr := `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <string name="greeting">%1$d messages pour %2$s</string>
+    <string name="price">%2$s pour %1$.1f</string>
+    <string name="count">%x éléments</string>
+</resources>
+`
+	de := `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <string name="greeting">Hallo %1$s, %3$d Nachrichten</string>
+    <string name="price">%1$d für %2$s</string>
+</resources>
+`
+	l := loadProject(t, map[string]string{
+		"values/strings.xml":    def,
+		"values-de/strings.xml": de,
+		"values-fr/strings.xml": fr,
+	})
+	var got []string
+	for _, err := range l.ValidatePlaceholders() {
+		got = append(got, err.Error())
+	}
+	want := []string{
+		// integer conversions are compatible, so it is not a type error
+		"count: placeholders for 'fr' are [%1$x], default has [%1$d]",
+		"greeting: placeholders for 'de' are [%1$s %3$d], default has [%1$s %2$d]",
+		// swapped arguments of different types are reported by index only
+		"greeting: arg 1 is %s in def but %d in fr",
+		"greeting: arg 2 is %d in def but %s in fr",
+		"price: arg 1 is %f in def but %d in de",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("errors are\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
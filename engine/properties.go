@@ -0,0 +1,324 @@
+package engine
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	propertiesBaseName = "messages"
+	propertiesExt      = ".properties"
+)
+
+//SetPropertiesUTF8 sets whether java properties files are written and read in UTF-8;
+//by default they are in ISO-8859-1 with other characters escaped as \uXXXX
+func (l *Localizer) SetPropertiesUTF8(unicode bool) *Localizer {
+	l.propertiesUTF8 = unicode
+	return l
+}
+
+//ExportProperties writes java messages_<locale>.properties file to dir for every locale (default locale
+//goes to messages.properties) with key=value lines in sorted order; values are plain text (see PlainText)
+func (l *Localizer) ExportProperties(dir string) error {
+	if l.err != nil {
+		return l.err
+	}
+	err := l.mkdirAllOf(dir)
+	if err != nil {
+		return err
+	}
+	for _, loc := range l.Locales {
+		err = l.createFile(filepath.Join(dir, propertiesFileName(loc)), func(w io.Writer) error {
+			return l.writeProperties(w, loc)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//ImportPropertiesDir imports values from messages_<locale>.properties files in dir
+//(see ImportProperties; messages.properties with default values is imported only if SetWriteDefault was called)
+func (l *Localizer) ImportPropertiesDir(dir string) error {
+	if l.err != nil {
+		return l.err
+	}
+	files, err := l.glob(filepath.Join(dir, propertiesBaseName+"*"+propertiesExt))
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+	for _, fileName := range files {
+		if err = l.ImportProperties(fileName, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//ImportProperties imports values of locale from java properties file; if locale is empty
+//it is guessed by file name (messages_pt_BR.properties is pt-rBR, messages.properties is default locale);
+//default values are imported only if SetWriteDefault was called
+func (l *Localizer) ImportProperties(fileName, locale string) error {
+	if l.err != nil {
+		return l.err
+	}
+	err := l.importPropertiesFile(fileName, locale)
+	if err != nil {
+		return &FileError{FileName: fileName, Err: err}
+	}
+	return nil
+}
+
+func (l *Localizer) importPropertiesFile(fileName, loc string) error {
+	var err error
+	if loc == "" {
+		loc, err = propertiesLocale(fileName)
+	} else if loc != defLocale {
+		loc, err = NormalizeLocale(loc)
+	}
+	if err != nil {
+		return err
+	}
+	if (loc == defLocale && !l.writeDefault) || (len(l.importLocales) > 0 && !contains(l.importLocales, loc)) {
+		l.logf("%s is skipped: locale '%s' is not imported", fileName, loc)
+		return nil
+	}
+	content, err := l.readFileOf(fileName)
+	if err != nil {
+		return err
+	}
+	props, err := parseProperties(content, l.propertiesUTF8)
+	if err != nil {
+		return err
+	}
+	values := map[*String]string{}
+	for _, p := range props {
+		if l.isExcluded(p.key) {
+			continue
+		}
+		s, ok := l.strings[p.key]
+		if !ok {
+			return &UnknownKeyError{Name: p.key, Source: "properties"}
+		}
+		if s.IsReference() || !s.Translatable {
+			continue
+		}
+		values[s] = AndroidValue(p.value)
+	}
+	l.addLocale(loc)
+	for s, v := range values {
+		if !l.keepsReference(s, loc, v) {
+			s.Values[loc] = normalizeValue(l.importedNewlines(v), s.Values[loc], s.Values[defLocale])
+		}
+	}
+	return nil
+}
+
+func propertiesFileName(loc string) string {
+	if loc == defLocale {
+		return propertiesBaseName + propertiesExt
+	}
+	return propertiesBaseName + "_" + strings.Replace(LanguageTag(loc), "-", "_", -1) + propertiesExt
+}
+
+// propertiesLocale returns locale of file by its name: messages_<locale>.properties
+func propertiesLocale(fileName string) (string, error) {
+	name := strings.TrimSuffix(filepath.Base(fileName), propertiesExt)
+	if name == propertiesBaseName {
+		return defLocale, nil
+	}
+	tag := strings.TrimPrefix(name, propertiesBaseName+"_")
+	if tag == name {
+		return "", fmt.Errorf("can not guess locale by file name: should be like %s", propertiesFileName("de"))
+	}
+	loc, err := NormalizeLocale(tag)
+	if err != nil {
+		// script subtags (sr_Latn)
+		return NormalizeLocale("b+" + strings.Replace(tag, "_", "+", -1))
+	}
+	return loc, nil
+}
+
+func (l *Localizer) writeProperties(w io.Writer, loc string) error {
+	bw := bufio.NewWriter(w)
+	for _, n := range l.sortedNames() {
+		s := l.strings[n]
+		v, ok := s.Values[loc]
+		if !l.isTranslatable(s) || !ok {
+			continue
+		}
+		bw.WriteString(escapeProperty(n, true, l.propertiesUTF8))
+		bw.WriteByte('=')
+		bw.WriteString(escapeProperty(PlainText(v), false, l.propertiesUTF8))
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+// escapeProperty escapes key or value for properties file; characters out of ISO-8859-1 printable ASCII
+// are escaped as \uXXXX unless unicode is true
+func escapeProperty(s string, key bool, unicode bool) string {
+	sb := strings.Builder{}
+	for i, c := range s {
+		switch c {
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\f':
+			sb.WriteString(`\f`)
+		case '=', ':', '#', '!':
+			sb.WriteByte('\\')
+			sb.WriteRune(c)
+		case ' ':
+			if key || i == 0 {
+				sb.WriteByte('\\')
+			}
+			sb.WriteRune(c)
+		default:
+			if c < 0x20 || (c > 0x7e && !unicode) {
+				for _, u := range utf16Units(c) {
+					fmt.Fprintf(&sb, `\u%04X`, u)
+				}
+				continue
+			}
+			sb.WriteRune(c)
+		}
+	}
+	return sb.String()
+}
+
+func utf16Units(c rune) []rune {
+	if c < 0x10000 {
+		return []rune{c}
+	}
+	c -= 0x10000
+	return []rune{0xD800 + (c>>10)&0x3FF, 0xDC00 + c&0x3FF}
+}
+
+type property struct {
+	key, value string
+}
+
+// parseProperties parses content of java properties file (ISO-8859-1 unless unicode is true)
+func parseProperties(content []byte, unicode bool) ([]property, error) {
+	text := string(content)
+	if !unicode {
+		runes := make([]rune, len(content))
+		for i, b := range content {
+			runes[i] = rune(b)
+		}
+		text = string(runes)
+	}
+	var props []property
+	lines := strings.Split(strings.Replace(text, "\r\n", "\n", -1), "\n")
+	for i := 0; i < len(lines); i++ {
+		lineNo := i + 1
+		line := strings.TrimLeft(lines[i], " \t\f")
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+		// lines ending with odd count of backslashes are continued on the next line
+		for continued(line) && i+1 < len(lines) {
+			i++
+			line = line[:len(line)-1] + strings.TrimLeft(lines[i], " \t\f")
+		}
+		if continued(line) {
+			line = line[:len(line)-1]
+		}
+		sep := len(line)
+		for j := 0; j < len(line); j++ {
+			if line[j] == '\\' {
+				j++
+				continue
+			}
+			if strings.IndexByte("=: \t\f", line[j]) >= 0 {
+				sep = j
+				break
+			}
+		}
+		key, rest := line[:sep], strings.TrimLeft(line[sep:], " \t\f")
+		if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+			rest = strings.TrimLeft(rest[1:], " \t\f")
+		}
+		k, err := unescapeProperty(key)
+		if err == nil {
+			var v string
+			v, err = unescapeProperty(rest)
+			props = append(props, property{k, v})
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNo, err)
+		}
+	}
+	return props, nil
+}
+
+func continued(line string) bool {
+	n := len(line) - len(strings.TrimRight(line, `\`))
+	return n%2 == 1
+}
+
+func unescapeProperty(s string) (string, error) {
+	if strings.IndexByte(s, '\\') < 0 {
+		return s, nil
+	}
+	buf := bytes.Buffer{}
+	var surrogate rune
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' || i == len(s)-1 {
+			buf.WriteByte(c)
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			buf.WriteByte('\n')
+		case 't':
+			buf.WriteByte('\t')
+		case 'r':
+			buf.WriteByte('\r')
+		case 'f':
+			buf.WriteByte('\f')
+		case 'u':
+			if i+5 > len(s) {
+				return "", fmt.Errorf("invalid escape '%s'", s[i-1:])
+			}
+			u, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("invalid escape '%s'", s[i-1:i+5])
+			}
+			i += 4
+			r := rune(u)
+			switch {
+			case r >= 0xD800 && r < 0xDC00:
+				surrogate = r
+				continue
+			case r >= 0xDC00 && r < 0xE000 && surrogate != 0:
+				r = 0x10000 + (surrogate-0xD800)<<10 + (r - 0xDC00)
+			}
+			buf.WriteRune(r)
+		default:
+			buf.WriteByte(s[i])
+		}
+		surrogate = 0
+	}
+	if !utf8.Valid(buf.Bytes()) {
+		return "", fmt.Errorf("invalid characters in '%s'", s)
+	}
+	return buf.String(), nil
+}
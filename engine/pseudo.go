@@ -0,0 +1,128 @@
+package engine
+
+import (
+	"math"
+	"regexp"
+	"strings"
+)
+
+//PseudoOptions contains options of pseudo-localization
+type PseudoOptions struct {
+	//Expansion is ratio of length added to values (0.4 by default)
+	Expansion float64
+	//Brackets enables wrapping values in ⟦ and ⟧ (enabled by default)
+	Brackets bool
+	//ICU enables protection of ICU MessageFormat syntax (arguments, selectors) leaving only message text
+	//pseudo-localized; GeneratePseudo enables it for ICU strings (see SetICUPatterns)
+	ICU bool
+}
+
+var (
+	pseudoAccents = map[rune]rune{
+		'a': 'á', 'b': 'ƀ', 'c': 'ç', 'd': 'ď', 'e': 'é', 'f': 'ƒ', 'g': 'ĝ', 'h': 'ĥ', 'i': 'î', 'j': 'ĵ',
+		'k': 'ķ', 'l': 'ľ', 'm': 'ɱ', 'n': 'ñ', 'o': 'ö', 'p': 'þ', 'q': 'ǫ', 'r': 'ŕ', 's': 'š', 't': 'ţ',
+		'u': 'û', 'v': 'ṽ', 'w': 'ŵ', 'x': 'ẋ', 'y': 'ý', 'z': 'ž',
+		'A': 'Å', 'B': 'Ɓ', 'C': 'Ç', 'D': 'Ď', 'E': 'É', 'F': 'Ƒ', 'G': 'Ĝ', 'H': 'Ĥ', 'I': 'Î', 'J': 'Ĵ',
+		'K': 'Ķ', 'L': 'Ľ', 'M': 'Ṁ', 'N': 'Ñ', 'O': 'Ö', 'P': 'Þ', 'Q': 'Ǫ', 'R': 'Ŕ', 'S': 'Š', 'T': 'Ţ',
+		'U': 'Û', 'V': 'Ṽ', 'W': 'Ŵ', 'X': 'Ẋ', 'Y': 'Ý', 'Z': 'Ž',
+	}
+	pseudoPadding = strings.Fields("one two three four five six seven eight nine ten")
+	// parts of values that must not be changed (besides placeholders): xliff:g spans, tags, entities and android escapes
+	pseudoProtectedRegexp = regexp.MustCompile(`(?s)<xliff:g[^>]*>.*?</xliff:g>|<[^>]*>|&[^;\s]+;|\\u[0-9a-fA-F]{4}|\\.`)
+)
+
+//DefaultPseudoOptions are options of pseudo-localization used if none are given to GeneratePseudo
+var DefaultPseudoOptions = PseudoOptions{Expansion: 0.4, Brackets: true}
+
+//GeneratePseudo adds pseudo-locale targetLocale (e.g. en-XA) with values of translatable strings made from
+//default ones: letters are replaced with accented ones, values are padded and wrapped in brackets (markup,
+//xliff:g spans, escapes, placeholders and ICU syntax are left untouched); call Save to write them
+func (l *Localizer) GeneratePseudo(targetLocale string, opts ...PseudoOptions) error {
+	if l.err != nil {
+		return l.err
+	}
+	loc, err := NormalizeLocale(targetLocale)
+	if err != nil {
+		return err
+	}
+	o := DefaultPseudoOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	l.addLocale(loc)
+	for _, s := range l.strings {
+		if l.isTranslatable(s) {
+			so := o
+			so.ICU = o.ICU || l.isICU(s)
+			s.Values[loc] = Pseudolocalize(s.Values[defLocale], so)
+		}
+	}
+	return nil
+}
+
+//SetPseudoLocale sets pseudo-locale (e.g. en-XA) Save generates again from default values before writing
+//resources (see GeneratePseudo); empty locale disables generation
+func (l *Localizer) SetPseudoLocale(locale string, opts ...PseudoOptions) *Localizer {
+	l.pseudoLocale = locale
+	l.pseudoOptions = opts
+	return l
+}
+
+//Pseudolocalize returns pseudo-localized android resource value (see GeneratePseudo)
+func Pseudolocalize(value string, opts PseudoOptions) string {
+	protected := pseudoProtected(value, opts.ICU)
+	sb := strings.Builder{}
+	letters := 0
+	for i, r := range value {
+		if a, ok := pseudoAccents[r]; ok && !protected[i] {
+			r = a
+			letters++
+		}
+		sb.WriteRune(r)
+	}
+	res := sb.String()
+	if opts.Expansion > 0 && letters > 0 {
+		res = padPseudo(res, int(math.Ceil(float64(letters)*opts.Expansion)))
+	}
+	if opts.Brackets {
+		res = "⟦" + res + "⟧"
+	}
+	return res
+}
+
+// pseudoProtected returns mask of bytes of value that must not be changed
+func pseudoProtected(value string, icu bool) []bool {
+	protected := make([]bool, len(value))
+	for _, loc := range append(pseudoProtectedRegexp.FindAllStringIndex(value, -1), placeholderIndexes(value)...) {
+		for i := loc[0]; i < loc[1]; i++ {
+			protected[i] = true
+		}
+	}
+	if !icu {
+		return protected
+	}
+	// values that are not valid ICU messages are processed as plain ones
+	if mask, err := icuTextMask(value); err == nil {
+		n := 0
+		for i := range value {
+			protected[i] = protected[i] || !mask[n]
+			n++
+		}
+	}
+	return protected
+}
+
+func padPseudo(value string, n int) string {
+	// padding goes before closing quote of quoted value
+	suffix := ""
+	if len(value) > 1 && strings.HasSuffix(value, `"`) && !strings.HasSuffix(value, `\"`) {
+		value, suffix = value[:len(value)-1], `"`
+	}
+	pad := []string{}
+	for i, length := 0, 0; length < n; i++ {
+		w := pseudoPadding[i%len(pseudoPadding)]
+		pad = append(pad, w)
+		length += len(w) + 1
+	}
+	return value + " " + strings.Join(pad, " ") + suffix
+}
@@ -0,0 +1,62 @@
+package engine
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPseudolocalizeKeepsPlaceholders(t *testing.T) {
+	values := []string{
+		"Hello %1$s, you have %2$d messages",
+		"%s of %d (100%%)\\nnext line",
+		`Hi <b>%1$s</b> &amp; <xliff:g id="count" example="5">%2$d</xliff:g> l\'app`,
+		"%2$-5s before %1$.2f",
+	}
+	for _, v := range values {
+		got := Pseudolocalize(v, DefaultPseudoOptions)
+		if !strings.HasPrefix(got, "⟦") || !strings.HasSuffix(got, "⟧") {
+			t.Errorf("%q is not bracketed: %q", v, got)
+		}
+		if !reflect.DeepEqual(ParsePlaceholders(got), ParsePlaceholders(v)) {
+			t.Errorf("placeholders of %q are changed: %q", v, got)
+		}
+		if got == "⟦"+v+"⟧" {
+			t.Errorf("%q is not pseudo-localized", v)
+		}
+	}
+	got := Pseudolocalize(values[2], DefaultPseudoOptions)
+	for _, kept := range []string{"<b>", "</b>", "&amp;", `<xliff:g id="count" example="5">%2$d</xliff:g>`, `\'`} {
+		if !strings.Contains(got, kept) {
+			t.Errorf("%q is changed in %q", kept, got)
+		}
+	}
+	if text := PlainText(got); len([]rune(text)) < len([]rune(PlainText(values[2])))*13/10 {
+		t.Errorf("%q is not padded", got)
+	}
+}
+
+func TestPseudolocalizeICU(t *testing.T) {
+	v := "{count, plural, one {# file} other {# files}}"
+	got := Pseudolocalize(v, PseudoOptions{ICU: true})
+	if got != "{count, plural, one {# ƒîľé} other {# ƒîľéš}}" {
+		t.Errorf("ICU message is pseudo-localized as %q", got)
+	}
+}
+
+func TestGeneratePseudo(t *testing.T) {
+	l := loadProject(t, map[string]string{"values/strings.xml": testDefault})
+	if err := l.GeneratePseudo("en-XA"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Save(); err != nil {
+		t.Fatal(err)
+	}
+	got := readProjectFile(t, l, "values-en-rXA/strings.xml")
+	if !strings.Contains(got, `<string name="hello">⟦Ĥéľľö`) {
+		t.Errorf("pseudo-locale file is\n%s", got)
+	}
+	if strings.Contains(got, "app_name") {
+		t.Errorf("non-translatable string is pseudo-localized:\n%s", got)
+	}
+}
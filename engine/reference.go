@@ -0,0 +1,103 @@
+package engine
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const contextColumn = "context"
+
+var referenceRegexp = regexp.MustCompile(`^@(android:)?string/([A-Za-z0-9_.]+)$`)
+
+//ReferenceError describes string which default value refers to string that does not exist
+type ReferenceError struct {
+	Name string
+	Ref  string
+}
+
+func (e *ReferenceError) Error() string {
+	return fmt.Sprintf("%s: refers to missing string '%s'", e.Name, e.Ref)
+}
+
+//IsReference returns true if default value of string is reference to other string (e.g. "@string/app_name");
+//such strings are resolved at build time, so they are not exported, imported or copied to locale files
+func (s *String) IsReference() bool {
+	return IsReference(s.Values[defLocale])
+}
+
+//IsReference returns true if value is reference to string (e.g. "@string/app_name" or "@android:string/ok")
+func IsReference(value string) bool {
+	return referenceRegexp.MatchString(strings.TrimSpace(value))
+}
+
+//SetOverwriteReferences sets whether imports may replace locale values that are references to other strings
+//(by default such values are kept)
+func (l *Localizer) SetOverwriteReferences(overwrite bool) *Localizer {
+	l.overwriteReferences = overwrite
+	return l
+}
+
+// keepsReference returns true if value of string for locale is reference that import must not replace with value
+func (l *Localizer) keepsReference(s *String, loc, value string) bool {
+	old, ok := s.Values[loc]
+	if !ok || !IsReference(old) || l.overwriteReferences || strings.TrimSpace(value) == strings.TrimSpace(old) {
+		return false
+	}
+	l.logf("%s: reference value for '%s' is not overwritten", s.Name, loc)
+	return true
+}
+
+// referencedName returns name of project's string value refers to ("" for framework strings, e.g. @android:string/ok)
+func referencedName(value string) string {
+	m := referenceRegexp.FindStringSubmatch(strings.TrimSpace(value))
+	if m == nil || m[1] != "" {
+		return ""
+	}
+	return m[2]
+}
+
+//SetExportReferences sets whether strings referring to other strings are exported to csv
+//with resolved default value in additional context column (they are still ignored on import)
+func (l *Localizer) SetExportReferences(export bool) *Localizer {
+	l.exportReferences = export
+	return l
+}
+
+//CheckReferences returns *ReferenceError for every string which default value refers to missing string
+func (l *Localizer) CheckReferences() []error {
+	if l.err != nil {
+		return []error{l.err}
+	}
+	var errs []error
+	for _, n := range l.sortedNames() {
+		ref := referencedName(l.strings[n].Values[defLocale])
+		if ref == "" {
+			continue
+		}
+		if _, ok := l.strings[ref]; !ok {
+			errs = append(errs, &ReferenceError{Name: n, Ref: ref})
+		}
+	}
+	return errs
+}
+
+// resolveReference returns value for locale of string referred by s following chain of references
+func (l *Localizer) resolveReference(s *String, loc string) (string, bool) {
+	visited := map[string]bool{s.Name: true}
+	for {
+		ref := referencedName(s.Values[defLocale])
+		if ref == "" || visited[ref] {
+			return "", false
+		}
+		visited[ref] = true
+		target, ok := l.strings[ref]
+		if !ok {
+			return "", false
+		}
+		if !target.IsReference() {
+			return l.resolve(target, loc)
+		}
+		s = target
+	}
+}
@@ -0,0 +1,45 @@
+package engine
+
+import (
+	"fmt"
+	"regexp"
+)
+
+var resourceNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+//Rename renames string in all the locales keeping its values, comment and statuses;
+//next Save writes new name to default resources file (in place) and to locale files
+func (l *Localizer) Rename(oldName, newName string) error {
+	if l.err != nil {
+		return l.err
+	}
+	s, ok := l.strings[oldName]
+	if !ok {
+		return fmt.Errorf("string '%s' not found", oldName)
+	}
+	if _, ok := l.strings[newName]; ok {
+		return fmt.Errorf("string '%s' already exists", newName)
+	}
+	if !resourceNameRegexp.MatchString(newName) {
+		return fmt.Errorf("invalid string name '%s'", newName)
+	}
+	delete(l.strings, oldName)
+	s.Name = newName
+	l.strings[newName] = s
+	if l.renames == nil {
+		l.renames = map[string]string{}
+	}
+	// name in default resources file is still the original one
+	original := oldName
+	for o, n := range l.renames {
+		if n == oldName {
+			original = o
+		}
+	}
+	l.renames[original] = newName
+	if l.state != nil {
+		l.state.rename(oldName, newName)
+	}
+	l.logf("%s renamed to %s", oldName, newName)
+	return nil
+}
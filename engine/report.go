@@ -0,0 +1,97 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+//Report contains translation statistics and problems of project (see Localizer.Report); json field names are stable
+type Report struct {
+	//Project is path of project dir
+	Project      string `json:"project"`
+	ResourcesDir string `json:"resourcesDir"`
+	//Time is time report was made (UTC)
+	Time    time.Time      `json:"time"`
+	Locales []LocaleReport `json:"locales"`
+	//Issues are problems found by validation of values (placeholders, ICU messages, non-translatable strings)
+	Issues []ReportIssue `json:"issues"`
+}
+
+//LocaleReport contains translation statistics of locale
+type LocaleReport struct {
+	Locale     string  `json:"locale"`
+	Total      int     `json:"total"`
+	Translated int     `json:"translated"`
+	Missing    int     `json:"missing"`
+	Percent    float64 `json:"percent"`
+	//MissingKeys are sorted names of strings without translation
+	MissingKeys []string `json:"missingKeys"`
+	//StaleKeys are sorted names of strings which default value changed since translation (see StaleTranslations)
+	StaleKeys []string `json:"staleKeys"`
+}
+
+//ReportIssue describes problem found by validation
+type ReportIssue struct {
+	//Kind is placeholders, icu or non-translatable
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+//Report returns translation statistics (see Stats) with missing and stale strings by locale
+//and problems found by validation
+func (l *Localizer) Report() Report {
+	r := Report{Project: l.absPath(l.projectDir), ResourcesDir: l.ResourcesDir, Time: time.Now().UTC(),
+		Locales: []LocaleReport{}, Issues: []ReportIssue{}}
+	if l.err != nil {
+		return r
+	}
+	stale := l.StaleTranslations()
+	for _, st := range l.Stats() {
+		lr := LocaleReport{Locale: st.Locale, Total: st.Total, Translated: st.Translated, Missing: st.Missing(),
+			Percent: st.Percent(), MissingKeys: l.Missing(st.Locale), StaleKeys: stale[st.Locale]}
+		if lr.StaleKeys == nil {
+			lr.StaleKeys = []string{}
+		}
+		r.Locales = append(r.Locales, lr)
+	}
+	for _, v := range []struct {
+		kind string
+		errs []error
+	}{
+		{"placeholders", l.ValidatePlaceholders()},
+		{"icu", l.ValidateICU()},
+		{"newlines", l.ValidateNewlines()},
+		{"non-translatable", l.NonTranslatableValues()},
+	} {
+		for _, e := range v.errs {
+			r.Issues = append(r.Issues, ReportIssue{Kind: v.kind, Message: e.Error()})
+		}
+	}
+	return r
+}
+
+//ReportJSON writes report (see Report) to w as json
+func (l *Localizer) ReportJSON(w io.Writer) error {
+	if l.err != nil {
+		return l.err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", xmlIndent)
+	enc.SetEscapeHTML(false)
+	return enc.Encode(l.Report())
+}
+
+//WriteTable writes statistics of report as table (one line per locale)
+func (r Report) WriteTable(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "%-10s %8s %10s %8s %8s\n", "locale", "total", "translated", "missing", "percent"); err != nil {
+		return err
+	}
+	for _, lr := range r.Locales {
+		if _, err := fmt.Fprintf(w, "%-10s %8d %10d %8d %7.1f%%\n", lr.Locale, lr.Total, lr.Translated, lr.Missing, lr.Percent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestQualifiedDefaultDir(t *testing.T) {
+	l := loadProject(t, map[string]string{
+		"values-en/strings.xml": testDefault,
+		"values-de/strings.xml": testGerman,
+	})
+	if contains(l.Locales, "en") {
+		t.Errorf("default dir is loaded as locale: %v", l.Locales)
+	}
+	if !contains(l.Locales, "de") {
+		t.Errorf("locale is not found: %v", l.Locales)
+	}
+	s := l.Strings()["hello"]
+	if s.Values[defLocale] != "Hello" || s.Values["de"] != "Hallo" {
+		t.Errorf("values are %v", s.Values)
+	}
+	if lang := l.defaultLanguage(); lang != "en" {
+		t.Errorf("default language is %s", lang)
+	}
+	if err := l.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(l.ResourcesDir, valuesDir)); !os.IsNotExist(err) {
+		t.Errorf("bare values dir is created on save: %v", err)
+	}
+	if got := readProjectFile(t, l, "values-en/strings.xml"); got != testDefault {
+		t.Errorf("default file is changed:\n%s", got)
+	}
+}
+
+func TestNotResourcesDir(t *testing.T) {
+	dir := writeProject(t, map[string]string{"values-de/other.xml": testGerman})
+	if _, err := NewE(dir); err == nil {
+		t.Error("dir without strings files is accepted")
+	}
+}
+
+func TestGuessLocalesSkipsOtherQualifiers(t *testing.T) {
+	files := map[string]string{
+		"values/strings.xml":        testDefault,
+		"values-de/strings.xml":     testGerman,
+		"values-night/colors.xml":   "<resources/>",
+		"values-v21/styles.xml":     "<resources/>",
+		"values-sw600dp/dimens.xml": "<resources/>",
+		"values-es-rMX/strings.xml": testGerman,
+	}
+	l := loadProject(t, files)
+	if got := strings.Join(l.Locales, ","); got != "def,de,es-rMX" {
+		t.Errorf("locales are %s", got)
+	}
+	if err := l.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(l.ResourcesDir, "values-night", stringsFile)); !os.IsNotExist(err) {
+		t.Errorf("strings file is written to values-night: %v", err)
+	}
+}
+
+func TestMissingStringsFileOfLocale(t *testing.T) {
+	files := map[string]string{"values/strings.xml": testDefault, "values-fr/colors.xml": "<resources/>"}
+	dir := writeProject(t, files)
+	if err := New(dir).Load().Err(); err == nil {
+		t.Error("found locale without strings file is loaded")
+	}
+	if err := New(dir, "fr").Load().Err(); err != nil {
+		t.Errorf("requested locale without strings file: %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package engine
+
+import "strings"
+
+const (
+	//StatusApproved is status of values approved by reviewer
+	StatusApproved = "approved"
+	//StatusNeedsReview is status of values that should be reviewed
+	StatusNeedsReview = "needs-review"
+)
+
+const (
+	// statusColumnPrefix is prefix of csv columns with statuses of locale values (e.g. status:de)
+	statusColumnPrefix = "status:"
+	// statusColumnSuffix is suffix of status columns of other tools (e.g. fr_status)
+	statusColumnSuffix = "_status"
+)
+
+//NeedsReview returns true if value of locale needs review: it has StatusNeedsReview status
+//or was filled automatically (StatusMachine, StatusMemory)
+func (s *String) NeedsReview(loc string) bool {
+	switch s.Status[loc] {
+	case StatusNeedsReview, StatusMachine, StatusMemory:
+		return true
+	}
+	return false
+}
+
+//SetReviewIncomplete sets whether values that need review (see String.NeedsReview) are counted
+//as missing by Stats, Missing and CheckCoverage
+func (l *Localizer) SetReviewIncomplete(incomplete bool) *Localizer {
+	l.reviewIncomplete = incomplete
+	return l
+}
+
+// isComplete returns true if string has value for locale that counts as translated (see String.IsTranslated)
+func (l *Localizer) isComplete(s *String, loc string) bool {
+	return s.IsTranslated(loc) && !(l.reviewIncomplete && s.NeedsReview(loc))
+}
+
+// statusLocales returns those of locales that have statuses of values
+func (l *Localizer) statusLocales(locales []string) []string {
+	var res []string
+	for _, loc := range locales {
+		for _, s := range l.strings {
+			if s.Status[loc] != "" {
+				res = append(res, loc)
+				break
+			}
+		}
+	}
+	return res
+}
+
+// statusColumnLocale returns locale of csv column with statuses of values (status:fr or fr_status)
+func (l *Localizer) statusColumnLocale(header string) (string, bool) {
+	switch {
+	case strings.HasPrefix(header, statusColumnPrefix):
+		return l.columnLocale(strings.TrimPrefix(header, statusColumnPrefix))
+	case strings.HasSuffix(header, statusColumnSuffix):
+		return l.columnLocale(strings.TrimSuffix(header, statusColumnSuffix))
+	}
+	return "", false
+}
@@ -0,0 +1,110 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+//RowOrder defines order of csv rows
+type RowOrder int
+
+const (
+	//OrderAlpha sorts rows by names of strings (default)
+	OrderAlpha RowOrder = iota
+	//OrderSource keeps order of strings in resource files
+	OrderSource
+	//OrderGroup groups related strings (see SetRowGroups) keeping source order inside of groups
+	OrderGroup
+)
+
+//ParseRowOrder returns RowOrder by its name: alpha, order (source order) or group
+func ParseRowOrder(name string) (RowOrder, error) {
+	switch name {
+	case "alpha", "":
+		return OrderAlpha, nil
+	case "order":
+		return OrderSource, nil
+	case "group":
+		return OrderGroup, nil
+	}
+	return OrderAlpha, fmt.Errorf("invalid sort '%s': should be alpha, order or group", name)
+}
+
+//SetRowOrder sets order of rows of csv exports
+func (l *Localizer) SetRowOrder(order RowOrder) *Localizer {
+	l.rowOrder = order
+	return l
+}
+
+//SetRowGroups sets name prefixes of groups of rows for OrderGroup (e.g. onboarding_, settings_):
+//groups go in given order, other strings are grouped by name part before the first underscore after them
+func (l *Localizer) SetRowGroups(prefixes ...string) *Localizer {
+	l.rowGroup = func(name string) string {
+		for _, p := range prefixes {
+			if strings.HasPrefix(name, p) {
+				return p
+			}
+		}
+		return namePrefix(name)
+	}
+	l.rowGroupOrder = prefixes
+	return l
+}
+
+//SetRowGroupFunc sets function returning group of string by its name for OrderGroup;
+//groups go in order of their first strings in resource files
+func (l *Localizer) SetRowGroupFunc(group func(name string) string) *Localizer {
+	l.rowGroup = group
+	l.rowGroupOrder = nil
+	return l
+}
+
+// rowNames returns names of strings in order of csv rows
+func (l *Localizer) rowNames() []string {
+	names := l.sortedNames()
+	if l.rowOrder == OrderAlpha {
+		return names
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		return l.strings[names[i]].order < l.strings[names[j]].order
+	})
+	if l.rowOrder == OrderSource {
+		return names
+	}
+	rank := l.groupRanks(names)
+	sort.SliceStable(names, func(i, j int) bool {
+		return rank[l.rowGroupOf(names[i])] < rank[l.rowGroupOf(names[j])]
+	})
+	return names
+}
+
+// rowGroupOf returns group of string for OrderGroup
+func (l *Localizer) rowGroupOf(name string) string {
+	if l.rowGroup == nil {
+		return namePrefix(name)
+	}
+	return l.rowGroup(name)
+}
+
+// groupRanks returns ranks of groups of names (in source order): groups set by SetRowGroups go first
+func (l *Localizer) groupRanks(names []string) map[string]int {
+	rank := map[string]int{}
+	for i, g := range l.rowGroupOrder {
+		rank[g] = i
+	}
+	for _, n := range names {
+		if _, ok := rank[l.rowGroupOf(n)]; !ok {
+			rank[l.rowGroupOf(n)] = len(rank)
+		}
+	}
+	return rank
+}
+
+// namePrefix returns part of name before the first underscore (onboarding for onboarding_title)
+func namePrefix(name string) string {
+	if i := strings.Index(name, "_"); i > 0 {
+		return name[:i]
+	}
+	return name
+}
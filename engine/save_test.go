@@ -0,0 +1,84 @@
+package engine
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+const testEmptyDefault = `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <string name="empty"></string>
+    <string name="empty_translated"></string>
+    <string name="hello">Hello</string>
+</resources>
+`
+
+const testEmptyGerman = `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <string name="empty_translated">Leer</string>
+    <string name="hello">Hallo</string>
+</resources>
+`
+
+func TestSaveSkipsEmptyDefault(t *testing.T) {
+	l := loadProject(t, map[string]string{
+		"values/strings.xml":    testEmptyDefault,
+		"values-de/strings.xml": testEmptyGerman,
+	})
+	if err := l.Save(); err != nil {
+		t.Fatal(err)
+	}
+	de := readProjectFile(t, l, "values-de/strings.xml")
+	if strings.Contains(de, `"empty"`) {
+		t.Errorf("string with empty default is written:\n%s", de)
+	}
+	if !strings.Contains(de, `<string name="empty_translated">Leer</string>`) {
+		t.Errorf("translation of string with empty default is not written:\n%s", de)
+	}
+	warnings := l.SaveWarnings()
+	var ev *EmptyValueError
+	if len(warnings) != 1 || !errors.As(warnings[0], &ev) || ev.Name != "empty" || ev.Locale != "de" {
+		t.Errorf("save warnings are %v", warnings)
+	}
+}
+
+func TestSaveStrictFailsOnEmptyDefault(t *testing.T) {
+	l := loadProject(t, map[string]string{
+		"values/strings.xml":    testEmptyDefault,
+		"values-de/strings.xml": testEmptyGerman,
+	})
+	if err := l.ImportR(strings.NewReader("id,def,de\nhello,Hello,Hallo!\n")); err != nil {
+		t.Fatal(err)
+	}
+	err := l.SetStrict(true).Save()
+	var ev *EmptyValueError
+	if !errors.As(err, &ev) || ev.Name != "empty" {
+		t.Fatalf("strict save returned %v", err)
+	}
+	if de := readProjectFile(t, l, "values-de/strings.xml"); de != testEmptyGerman {
+		t.Errorf("file is written by failed save:\n%s", de)
+	}
+}
+
+// readOnlyDirsFS is filesystem dirs can not be created in
+type readOnlyDirsFS struct {
+	memFS
+}
+
+func (readOnlyDirsFS) MkdirAll(name string, perm fs.FileMode) error {
+	return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrPermission}
+}
+
+func TestSaveReportsLocaleDirError(t *testing.T) {
+	mem := readOnlyDirsFS{memFS{fstest.MapFS{"res/values/strings.xml": {Data: []byte(testDefault)}}}}
+	err := NewFromFS(mem, "res", "fr").Load().Save()
+	if err == nil || err.Error() != "could not create locale directory values-fr: permission denied" {
+		t.Errorf("save returned %v", err)
+	}
+	if _, ok := mem.MapFS["res/values-fr/strings.xml"]; ok {
+		t.Error("locale file is written")
+	}
+}
@@ -0,0 +1,287 @@
+package engine
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+)
+
+const stateFile = ".localizer-state.json"
+
+//State contains data kept between runs in sidecar file (see ReadState)
+type State struct {
+	//Exported contains hashes of default values by string name at the moment of last export
+	Exported map[string]string `json:"exported"`
+	//Translated contains records of translations by string name by locale
+	Translated map[string]map[string]TranslationState `json:"translated,omitempty"`
+	//Copied contains hashes of values written to locale files in place of missing translations (default
+	//or fallback locale values) by string name by locale; Load takes such values for missing translations
+	Copied map[string]map[string]string `json:"copied,omitempty"`
+}
+
+//TranslationState contains hashes of translated value and of default value it was translated from
+//and status of the value (e.g. StatusNeedsReview)
+type TranslationState struct {
+	Default string `json:"def"`
+	Value   string `json:"value"`
+	Status  string `json:"status,omitempty"`
+}
+
+//StateFileName returns default path of state file (in resources dir)
+func (l *Localizer) StateFileName() string {
+	return filepath.Join(l.ResourcesDir, stateFile)
+}
+
+//LoadState reads state from os file; empty state is returned if the file does not exist
+//(see Localizer.ReadState for file of engine's filesystem)
+func LoadState(fileName string) (*State, error) {
+	return readState(func() (io.ReadCloser, error) {
+		return os.Open(fileName)
+	})
+}
+
+//ReadState reads state from file of engine's filesystem; empty state is returned if the file does not exist
+func (l *Localizer) ReadState(fileName string) (*State, error) {
+	if l.err != nil {
+		return nil, l.err
+	}
+	return readState(func() (io.ReadCloser, error) {
+		return l.openFile(fileName)
+	})
+}
+
+func readState(open func() (io.ReadCloser, error)) (*State, error) {
+	st := &State{}
+	f, err := open()
+	if err == nil {
+		defer f.Close()
+		err = json.NewDecoder(f).Decode(st)
+	} else if os.IsNotExist(err) {
+		err = nil
+	}
+	if st.Exported == nil {
+		st.Exported = map[string]string{}
+	}
+	if st.Translated == nil {
+		st.Translated = map[string]map[string]TranslationState{}
+	}
+	if st.Copied == nil {
+		st.Copied = map[string]map[string]string{}
+	}
+	return st, err
+}
+
+//Save writes state to os file (see Localizer.WriteState for file of engine's filesystem)
+func (st *State) Save(fileName string) error {
+	return writeFile(fileName, st.write)
+}
+
+//WriteState writes state to file of engine's filesystem
+func (l *Localizer) WriteState(st *State, fileName string) error {
+	if l.err != nil {
+		return l.err
+	}
+	return l.createFile(fileName, st.write)
+}
+
+func (st *State) write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", xmlIndent)
+	return enc.Encode(st)
+}
+
+// loadState reads state of engine from state file in resources dir
+func (l *Localizer) loadState() (*State, error) {
+	return readState(func() (io.ReadCloser, error) {
+		return l.open(l.StateFileName())
+	})
+}
+
+// saveState writes state of engine to state file in resources dir
+func (l *Localizer) saveState() error {
+	buf := &bytes.Buffer{}
+	if err := l.state.write(buf); err != nil {
+		return err
+	}
+	return l.writeResourceFile(l.StateFileName(), buf.Bytes())
+}
+
+//ExportChanged exports to csv file strings changed since export recorded in since (see ExportChangedW)
+func (l *Localizer) ExportChanged(fileName string, since *State) error {
+	if l.err != nil {
+		return l.err
+	}
+	return l.exportFile(fileName, func(w io.Writer) error {
+		return l.ExportChangedW(w, since)
+	})
+}
+
+//ExportChangedW writes in csv format only strings whose default value is new or changed
+//since export recorded in since; since is updated with current default values
+func (l *Localizer) ExportChangedW(w io.Writer, since *State) error {
+	if l.err != nil {
+		return l.err
+	}
+	current := map[string]string{}
+	for n, s := range l.strings {
+		if l.isTranslatable(s) {
+			current[n] = valueHash(s.Values[defLocale])
+		}
+	}
+	err := l.writeCSVFiltered(w, l.delimiter(), l.Locales[1:], false, func(s *String) bool {
+		return since.Exported[s.Name] != current[s.Name]
+	})
+	if err == nil {
+		since.Exported = current
+	}
+	return err
+}
+
+//StaleTranslations returns sorted names of strings by locale whose default value changed
+//since their translation was saved (translations changed after that are not stale)
+func (l *Localizer) StaleTranslations() map[string][]string {
+	res := map[string][]string{}
+	if l.err != nil {
+		return res
+	}
+	for _, loc := range l.Locales[1:] {
+		for _, n := range l.sortedNames() {
+			if l.isStale(l.strings[n], loc) {
+				res[loc] = append(res[loc], n)
+			}
+		}
+	}
+	return res
+}
+
+func (l *Localizer) isStale(s *String, loc string) bool {
+	if l.state == nil || !l.isTranslatable(s) || !s.IsTranslated(loc) {
+		return false
+	}
+	rec, ok := l.state.Translated[loc][s.Name]
+	return ok && rec.Value == valueHash(s.Values[loc]) && rec.Default != valueHash(s.Values[defLocale])
+}
+
+//ExportStale exports to csv file strings that are missing or stale in any locale (see ExportStaleW)
+func (l *Localizer) ExportStale(fileName string) error {
+	if l.err != nil {
+		return l.err
+	}
+	return l.exportFile(fileName, l.ExportStaleW)
+}
+
+//ExportStaleW writes in csv format only strings that are missing or stale (see StaleTranslations) in any locale
+func (l *Localizer) ExportStaleW(w io.Writer) error {
+	if l.err != nil {
+		return l.err
+	}
+	return l.writeCSVFiltered(w, l.delimiter(), l.Locales[1:], false, func(s *String) bool {
+		for _, loc := range l.Locales[1:] {
+			if !s.IsTranslated(loc) || l.isStale(s, loc) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// recordTranslations records default values new and changed translations are made from
+// and returns true if state was changed
+func (l *Localizer) recordTranslations() bool {
+	changed := false
+	for _, loc := range l.Locales[1:] {
+		recs := l.state.Translated[loc]
+		if recs == nil {
+			recs = map[string]TranslationState{}
+			l.state.Translated[loc] = recs
+		}
+		for n, s := range l.strings {
+			if !l.isTranslatable(s) || !s.IsTranslated(loc) {
+				continue
+			}
+			value := valueHash(s.Values[loc])
+			if rec, ok := recs[n]; !ok || rec.Value != value {
+				recs[n] = TranslationState{Default: valueHash(s.Values[defLocale]), Value: value, Status: s.Status[loc]}
+				changed = true
+			} else if rec.Status != s.Status[loc] {
+				rec.Status = s.Status[loc]
+				recs[n] = rec
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// recordCopies records values of files of locales written in place of missing translations
+// and returns true if state was changed
+func (l *Localizer) recordCopies(files []*xStrings) bool {
+	changed := false
+	for i, loc := range l.Locales {
+		if files[i] == nil {
+			continue
+		}
+		copies := map[string]string{}
+		for _, str := range files[i].Strings {
+			if str.copied {
+				copies[str.Name] = valueHash(str.Value)
+			}
+		}
+		if !reflect.DeepEqual(copies, l.state.Copied[loc]) && (len(copies) > 0 || len(l.state.Copied[loc]) > 0) {
+			if len(copies) == 0 {
+				delete(l.state.Copied, loc)
+			} else {
+				l.state.Copied[loc] = copies
+			}
+			changed = true
+		}
+	}
+	return changed
+}
+
+// isCopy returns true if value of locale file is the one Save wrote in place of missing translation
+func (l *Localizer) isCopy(name, loc, value string) bool {
+	h, ok := l.state.Copied[loc][name]
+	return ok && h == valueHash(value)
+}
+
+// applyStatuses sets statuses of values recorded in state if values were not changed since
+func (l *Localizer) applyStatuses() {
+	for loc, recs := range l.state.Translated {
+		for n, rec := range recs {
+			if s, ok := l.strings[n]; ok && rec.Status != "" && rec.Value == valueHash(s.Values[loc]) {
+				s.SetStatus(loc, rec.Status)
+			}
+		}
+	}
+}
+
+// rename moves records of string to new name
+func (st *State) rename(oldName, newName string) {
+	if h, ok := st.Exported[oldName]; ok {
+		st.Exported[newName] = h
+		delete(st.Exported, oldName)
+	}
+	for _, recs := range st.Translated {
+		if rec, ok := recs[oldName]; ok {
+			recs[newName] = rec
+			delete(recs, oldName)
+		}
+	}
+	for _, copies := range st.Copied {
+		if h, ok := copies[oldName]; ok {
+			copies[newName] = h
+			delete(copies, oldName)
+		}
+	}
+}
+
+func valueHash(v string) string {
+	h := sha256.Sum256([]byte(v))
+	return hex.EncodeToString(h[:8])
+}
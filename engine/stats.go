@@ -0,0 +1,104 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+)
+
+// LocaleStats contains translation statistics for locale
+type LocaleStats struct {
+	Locale string
+	Total  int
+	//Translated is count of strings translated to locale (see String.IsTranslated)
+	Translated int
+}
+
+// Missing returns count of untranslated strings
+func (s LocaleStats) Missing() int {
+	return s.Total - s.Translated
+}
+
+// Percent returns percent of translated strings
+func (s LocaleStats) Percent() float64 {
+	if s.Total == 0 {
+		return 100
+	}
+	return float64(s.Translated) * 100 / float64(s.Total)
+}
+
+// Stats returns translation statistics for all non-default locales
+func (l *Localizer) Stats() []LocaleStats {
+	res := []LocaleStats{}
+	for _, loc := range l.Locales[1:] {
+		res = append(res, l.localeStats(loc))
+	}
+	return res
+}
+
+func (l *Localizer) localeStats(loc string) LocaleStats {
+	st := LocaleStats{Locale: loc}
+	for _, s := range l.strings {
+		if l.isTranslatable(s) && !isForeignQuantity(s, loc) {
+			st.Total++
+			if l.isComplete(s, loc) {
+				st.Translated++
+			}
+		}
+	}
+	return st
+}
+
+// CoverageError describes locale which percent of translated strings is below required one
+type CoverageError struct {
+	Locale   string
+	Percent  float64
+	Required float64
+}
+
+func (e *CoverageError) Error() string {
+	return fmt.Sprintf("%s: %.1f%% complete, required %.1f%% (%.1f%% short)", e.Locale, e.Percent, e.Required, e.Required-e.Percent)
+}
+
+// CheckCoverage returns *CoverageError for every locale with percent of translated strings below its threshold;
+// thresholds are percents by locale (locales that are not found in project are checked too),
+// min is threshold of the rest of locales (negative means they are not checked)
+func (l *Localizer) CheckCoverage(min float64, thresholds map[string]float64) []error {
+	if l.err != nil {
+		return []error{l.err}
+	}
+	required := map[string]float64{}
+	for _, loc := range l.Locales[1:] {
+		if min >= 0 {
+			required[loc] = min
+		}
+	}
+	for loc, t := range thresholds {
+		required[loc] = t
+	}
+	locales := make([]string, 0, len(required))
+	for loc := range required {
+		locales = append(locales, loc)
+	}
+	sort.Strings(locales)
+	var errs []error
+	for _, loc := range locales {
+		st := l.localeStats(loc)
+		if st.Percent() < required[loc] {
+			errs = append(errs, &CoverageError{Locale: loc, Percent: st.Percent(), Required: required[loc]})
+		}
+	}
+	return errs
+}
+
+// Missing returns sorted names of translatable strings without value for locale or with copy of default value
+// (or with value that needs review if SetReviewIncomplete was called)
+func (l *Localizer) Missing(locale string) []string {
+	res := []string{}
+	for _, n := range l.sortedNames() {
+		s := l.strings[n]
+		if l.isTranslatable(s) && !isForeignQuantity(s, locale) && !l.isComplete(s, locale) {
+			res = append(res, n)
+		}
+	}
+	return res
+}
@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testGerman = `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <string name="hello">Hallo</string>
+</resources>
+`
+
+// savedProject returns engine with project of testDefault and testGerman reloaded after Save
+// (which copies default values of missing translations to values-de)
+func savedProject(t *testing.T) *Localizer {
+	t.Helper()
+	l := loadProject(t, map[string]string{"values/strings.xml": testDefault, "values-de/strings.xml": testGerman})
+	if err := l.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if de := readProjectFile(t, l, "values-de/strings.xml"); !strings.Contains(de, `<string name="bye">Bye</string>`) {
+		t.Fatalf("default value is not copied:\n%s", de)
+	}
+	l = New(l.ResourcesDir).Load()
+	if err := l.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func TestStatsCountCopiedDefaultsAsMissing(t *testing.T) {
+	l := savedProject(t)
+	st := l.Stats()
+	if len(st) != 1 || st[0].Total != 2 || st[0].Translated != 1 {
+		t.Errorf("stats are %+v", st)
+	}
+	if missing := l.Missing("de"); !reflect.DeepEqual(missing, []string{"bye"}) {
+		t.Errorf("missing are %v", missing)
+	}
+}
+
+func TestCheckCoverageFailsOnCopiedDefaults(t *testing.T) {
+	l := savedProject(t)
+	errs := l.CheckCoverage(95, nil)
+	if len(errs) != 1 {
+		t.Fatalf("errors are %v", errs)
+	}
+	if e, ok := errs[0].(*CoverageError); !ok || e.Locale != "de" || e.Percent != 50 {
+		t.Errorf("error is %v", errs[0])
+	}
+	if errs = l.CheckCoverage(50, nil); len(errs) != 0 {
+		t.Errorf("errors are %v", errs)
+	}
+}
+
+func TestIdenticalTranslationIsTranslated(t *testing.T) {
+	// human translation equal to default value is not a copy
+	de := `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <string name="hello">Hallo</string>
+    <string name="bye">Bye</string>
+</resources>
+`
+	l := loadProject(t, map[string]string{"values/strings.xml": testDefault, "values-de/strings.xml": de})
+	if err := l.Save(); err != nil {
+		t.Fatal(err)
+	}
+	l = New(l.ResourcesDir).Load()
+	if missing := l.Missing("de"); len(missing) != 0 {
+		t.Errorf("missing are %v", missing)
+	}
+	if errs := l.CheckCoverage(100, nil); len(errs) != 0 {
+		t.Errorf("errors are %v", errs)
+	}
+}
+
+func TestCopyBecomesTranslation(t *testing.T) {
+	l := savedProject(t)
+	// value equal to default imported explicitly is a translation
+	if err := l.ImportR(strings.NewReader("id,def,de\nbye,Bye,Bye\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Save(); err != nil {
+		t.Fatal(err)
+	}
+	l = New(l.ResourcesDir).Load()
+	if missing := l.Missing("de"); len(missing) != 0 {
+		t.Errorf("missing are %v", missing)
+	}
+}
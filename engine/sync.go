@@ -0,0 +1,167 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+//SyncReport contains changes made by Sync
+type SyncReport struct {
+	//AddedToCSV contains sorted names of strings added to csv
+	AddedToCSV []string
+	//Updated contains sorted names of strings which values were updated in resources by locale
+	Updated map[string][]string
+	//RemovedFromCSV contains sorted names of csv rows dropped because strings are not in resources anymore
+	RemovedFromCSV []string
+}
+
+func (r SyncReport) String() string {
+	updated := 0
+	for _, names := range r.Updated {
+		updated += len(names)
+	}
+	return fmt.Sprintf("%d strings added to csv, %d values updated in resources, %d strings removed from csv",
+		len(r.AddedToCSV), updated, len(r.RemovedFromCSV))
+}
+
+//Sync merges csv file and resources in both directions: non-empty cells of csv that differ from resources
+//are applied to resources (empty cells do not clear values), then resources are saved and csv is written again
+//with all the strings (new ones get default values); csv that does not exist yet is created.
+//Rows of strings that are not in resources anymore are dropped from csv (see SyncReport.RemovedFromCSV).
+//Columns that are not locales are not kept in csv
+func (l *Localizer) Sync(csvPath string) (SyncReport, error) {
+	rep := SyncReport{AddedToCSV: []string{}, Updated: map[string][]string{}, RemovedFromCSV: []string{}}
+	if l.err != nil {
+		return rep, l.err
+	}
+	content, err := l.readFileOf(csvPath)
+	if err != nil && !os.IsNotExist(err) {
+		return rep, err
+	}
+	inCSV := map[string]bool{}
+	if err == nil {
+		if inCSV, err = csvNames(content, l.delimiter()); err != nil {
+			return rep, err
+		}
+		if content, rep.RemovedFromCSV, err = l.withoutRemoved(content); err != nil {
+			return rep, err
+		}
+		before := l.snapshot()
+		skip := l.skipEmptyCells
+		l.skipEmptyCells = true
+		err = l.readCSV(bytes.NewReader(content), l.delimiter(), l.importLocales)
+		l.skipEmptyCells = skip
+		if err != nil {
+			return rep, err
+		}
+		for _, n := range l.sortedNames() {
+			for loc, v := range l.strings[n].Values {
+				if old, ok := before[n][loc]; !ok || old != v {
+					rep.Updated[loc] = append(rep.Updated[loc], n)
+				}
+			}
+		}
+	}
+	for _, n := range l.sortedNames() {
+		if l.isTranslatable(l.strings[n]) && !inCSV[n] {
+			rep.AddedToCSV = append(rep.AddedToCSV, n)
+		}
+	}
+	if err = l.Save(); err != nil {
+		return rep, err
+	}
+	return rep, l.Export(csvPath)
+}
+
+// snapshot returns copy of values of strings by name
+func (l *Localizer) snapshot() map[string]map[string]string {
+	res := map[string]map[string]string{}
+	for n, s := range l.strings {
+		res[n] = map[string]string{}
+		for loc, v := range s.Values {
+			res[n][loc] = v
+		}
+	}
+	return res
+}
+
+// csvNames returns names of strings of csv content
+func csvNames(content []byte, comma rune) (map[string]bool, error) {
+	cr := csv.NewReader(bytes.NewReader(content))
+	cr.Comma = comma
+	cr.FieldsPerRecord = -1
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	names := map[string]bool{}
+	if len(rows) == 0 {
+		return names, nil
+	}
+	col := -1
+	for i, h := range rows[0] {
+		if h == nameColumn {
+			col = i
+		}
+	}
+	if col < 0 {
+		return nil, fmt.Errorf("%w: column '%s' is not found", ErrInvalidHeader, nameColumn)
+	}
+	for _, row := range rows[1:] {
+		if col < len(row) {
+			names[row[col]] = true
+		}
+	}
+	return names, nil
+}
+
+// withoutRemoved returns csv content without rows of strings that are not in resources (excluded strings
+// and plurals quantities that may be added are kept) and sorted names of dropped rows
+func (l *Localizer) withoutRemoved(content []byte) ([]byte, []string, error) {
+	cr := csv.NewReader(bytes.NewReader(content))
+	cr.Comma = l.delimiter()
+	cr.FieldsPerRecord = -1
+	rows, err := cr.ReadAll()
+	if err != nil || len(rows) == 0 {
+		return content, []string{}, err
+	}
+	col := -1
+	for i, h := range rows[0] {
+		if h == nameColumn {
+			col = i
+		}
+	}
+	removed := []string{}
+	kept := rows[:1]
+	for _, row := range rows[1:] {
+		if col < 0 || col >= len(row) {
+			kept = append(kept, row)
+			continue
+		}
+		name := strings.TrimPrefix(row[col], contextMark)
+		if _, ok := l.strings[name]; ok || l.isExcluded(name) {
+			kept = append(kept, row)
+			continue
+		}
+		if s, err := l.importedItem(name); s != nil || err != nil {
+			kept = append(kept, row)
+			continue
+		}
+		removed = append(removed, row[col])
+	}
+	sort.Strings(removed)
+	if len(removed) == 0 {
+		return content, removed, nil
+	}
+	buf := bytes.Buffer{}
+	cw := csv.NewWriter(&buf)
+	cw.Comma = l.delimiter()
+	if err = cw.WriteAll(kept); err != nil {
+		return nil, nil, err
+	}
+	return buf.Bytes(), removed, nil
+}
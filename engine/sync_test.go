@@ -0,0 +1,39 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSyncDropsRemovedStrings(t *testing.T) {
+	l := loadProject(t, map[string]string{"values/strings.xml": testDefault}, "de")
+	csvPath := filepath.Join(t.TempDir(), "strings.csv")
+	content := "id,def,de\nhello,Hello,Hallo\nremoved,Gone,Weg\n"
+	if err := os.WriteFile(csvPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	rep, err := l.Sync(csvPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rep.RemovedFromCSV, []string{"removed"}) {
+		t.Errorf("removed are %v", rep.RemovedFromCSV)
+	}
+	if !reflect.DeepEqual(rep.AddedToCSV, []string{"bye"}) {
+		t.Errorf("added are %v", rep.AddedToCSV)
+	}
+	if !reflect.DeepEqual(rep.Updated["de"], []string{"hello"}) {
+		t.Errorf("updated are %v", rep.Updated)
+	}
+	written, _ := os.ReadFile(csvPath)
+	if strings.Contains(string(written), "removed") || !strings.Contains(string(written), "bye") {
+		t.Errorf("csv is\n%s", written)
+	}
+	// the next sync works too
+	if rep, err = l.Sync(csvPath); err != nil || len(rep.RemovedFromCSV) != 0 {
+		t.Errorf("second sync: %v, %v", rep, err)
+	}
+}
@@ -0,0 +1,274 @@
+package engine
+
+import (
+	"encoding/xml"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// markupRegexp matches markup android keeps in string values (styling tags, xliff placeholders, comments
+// and CDATA sections) at the beginning of text
+var markupRegexp = regexp.MustCompile(`(?is)^(?:<!--.*?-->|<!\[CDATA\[.*?\]\]>|</?(?:a|annotation|b|big|blockquote|br|cite|del|dfn|div|em|font|h[1-6]|i|li|ol|p|s|small|span|strike|strong|sub|sup|tt|u|ul|xliff:g)\b[^<>]*>)`)
+
+//PlainText converts android resource value (raw xml content of element) to text shown to user:
+//markup is removed, entities are decoded, android escapes (\n, \', \uXXXX...) are processed
+//and quotes used for preserving whitespace are removed
+func PlainText(value string) string {
+	d := xml.NewDecoder(strings.NewReader("<v>" + value + "</v>"))
+	d.Strict = false
+	d.Entity = xml.HTMLEntity
+	sb := strings.Builder{}
+	for {
+		t, err := d.Token()
+		if err != nil {
+			break
+		}
+		if cd, ok := t.(xml.CharData); ok {
+			sb.Write(cd)
+		}
+	}
+	return unescapeAndroid(sb.String())
+}
+
+func unescapeAndroid(v string) string {
+	sb := strings.Builder{}
+	quoted := false
+	// outside of quotes whitespace runs are collapsed into one space, leading and trailing ones are removed
+	space := false
+	write := func(s string) {
+		if space && sb.Len() > 0 {
+			sb.WriteByte(' ')
+		}
+		space = false
+		sb.WriteString(s)
+	}
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		switch {
+		case c == '\\' && i+1 < len(v):
+			i++
+			switch v[i] {
+			case 'n':
+				write("\n")
+			case 't':
+				write("\t")
+			case 'u':
+				if i+5 <= len(v) {
+					if r, err := strconv.ParseUint(v[i+1:i+5], 16, 32); err == nil {
+						write(string(rune(r)))
+						i += 4
+						continue
+					}
+				}
+				write("u")
+			default:
+				write(v[i : i+1])
+			}
+		case c == '"':
+			quoted = !quoted
+		case !quoted && (c == ' ' || c == '\n' || c == '\t' || c == '\r'):
+			space = true
+		default:
+			write(v[i : i+1])
+		}
+	}
+	return sb.String()
+}
+
+//AndroidValue converts text to android resource value: xml special characters,
+//quotes, backslashes and leading @ and ? are escaped, newlines and tabs become \n and \t;
+//text with leading or trailing spaces is enclosed in double quotes, so android does not trim them
+func AndroidValue(text string) string {
+	sb := strings.Builder{}
+	quoted := text != strings.Trim(text, " ")
+	if quoted {
+		sb.WriteByte('"')
+	}
+	for i, c := range text {
+		switch c {
+		case '&':
+			sb.WriteString("&amp;")
+		case '<':
+			sb.WriteString("&lt;")
+		case '>':
+			sb.WriteString("&gt;")
+		case '\'', '"', '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(c)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '@', '?':
+			if i == 0 {
+				sb.WriteByte('\\')
+			}
+			sb.WriteRune(c)
+		default:
+			sb.WriteRune(c)
+		}
+	}
+	if quoted {
+		sb.WriteByte('"')
+	}
+	return sb.String()
+}
+
+// markupText converts android resource value to text like PlainText does but keeps markup tags
+// (<b>, <xliff:g id="user">) as they are, so translators of formats without markup see them; markupValue
+// converts it back
+func markupText(value string) string {
+	text, tags := hideMarkup(value)
+	return showMarkup(PlainText(text), tags)
+}
+
+// markupValue converts text with markup tags (see markupText) to android resource value like AndroidValue does
+// keeping the tags as they are
+func markupValue(text string) string {
+	text, tags := hideMarkup(text)
+	return showMarkup(AndroidValue(text), tags)
+}
+
+// firstMarkupRune is the first of private use runes markup tags are replaced with by hideMarkup
+const firstMarkupRune = '\ue000'
+
+// hideMarkup replaces markup tags of value (but not comments and CDATA sections) with private use runes
+// and returns them
+func hideMarkup(value string) (string, []string) {
+	var tags []string
+	sb := strings.Builder{}
+	for i := 0; i < len(value); i++ {
+		m := ""
+		if value[i] == '<' && !strings.HasPrefix(value[i:], "<!") {
+			m = markupRegexp.FindString(value[i:])
+		}
+		if m == "" {
+			sb.WriteByte(value[i])
+			continue
+		}
+		sb.WriteRune(firstMarkupRune + rune(len(tags)))
+		tags = append(tags, m)
+		i += len(m) - 1
+	}
+	return sb.String(), tags
+}
+
+// showMarkup restores markup tags replaced by hideMarkup
+func showMarkup(text string, tags []string) string {
+	if len(tags) == 0 {
+		return text
+	}
+	pairs := make([]string, 0, len(tags)*2)
+	for i, tag := range tags {
+		pairs = append(pairs, string(firstMarkupRune+rune(i)), tag)
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+// editableValue converts android resource value to form it is exported in for editing (e.g. to csv):
+// &amp;, &lt; and &gt; are decoded while markup and android escapes are kept as they are
+// (&lt; that would turn into markup is not decoded); resourceValue converts it back
+func editableValue(value string) string {
+	sb := strings.Builder{}
+	for i := 0; i < len(value); i++ {
+		switch {
+		case value[i] == '<':
+			end := len(value)
+			switch {
+			case strings.HasPrefix(value[i:], "<!--"):
+				if e := strings.Index(value[i:], "-->"); e >= 0 {
+					end = i + e + 3
+				}
+			case strings.HasPrefix(value[i:], "<![CDATA["):
+				if e := strings.Index(value[i:], "]]>"); e >= 0 {
+					end = i + e + 3
+				}
+			default:
+				if e := strings.IndexByte(value[i:], '>'); e >= 0 {
+					end = i + e + 1
+				}
+			}
+			sb.WriteString(value[i:end])
+			i = end - 1
+		case strings.HasPrefix(value[i:], "&amp;"):
+			sb.WriteByte('&')
+			i += 4
+		case strings.HasPrefix(value[i:], "&gt;"):
+			sb.WriteByte('>')
+			i += 3
+		case strings.HasPrefix(value[i:], "&lt;"):
+			rest := strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&").Replace(value[i:])
+			if markupRegexp.MatchString(rest) {
+				sb.WriteString("&lt;")
+			} else {
+				sb.WriteByte('<')
+			}
+			i += 3
+		default:
+			sb.WriteByte(value[i])
+		}
+	}
+	return sb.String()
+}
+
+// resourceValue converts value edited outside of resources (see editableValue) to android resource value:
+// &, < and > are escaped unless they are part of markup or entity, apostrophes and double quotes are escaped
+// with backslash unless they are escaped already; double quotes enclosing the whole value are kept
+// (whitespace inside of them is preserved by android) and apostrophes inside of them are not escaped
+func resourceValue(text string) string {
+	sb := strings.Builder{}
+	quoted := len(text) > 1 && text[0] == '"' && text[len(text)-1] == '"' && !strings.HasSuffix(text, `\"`)
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == '\\' && i+1 < len(text):
+			sb.WriteString(text[i : i+2])
+			i++
+		case c == '<':
+			if m := markupRegexp.FindString(text[i:]); m != "" {
+				sb.WriteString(m)
+				i += len(m) - 1
+			} else {
+				sb.WriteString("&lt;")
+			}
+		case c == '>':
+			sb.WriteString("&gt;")
+		case c == '&':
+			if entityRegexp.MatchString(text[i:]) {
+				sb.WriteByte(c)
+			} else {
+				sb.WriteString("&amp;")
+			}
+		case c == '"' && quoted && (i == 0 || i == len(text)-1):
+			sb.WriteByte(c)
+		case c == '"' || c == '\'' && !quoted:
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+//ImportedValue returns value edited outside of resources converted for locale of string the way imports do it:
+//special characters and quotes are escaped and newlines are normalized (see SetNormalizeNewlines),
+//value equal to the current or the default one (or to their editable forms) is returned unchanged
+func (l *Localizer) ImportedValue(s *String, loc, value string) string {
+	return normalizeValue(l.importedNewlines(value), s.Values[loc], s.Values[defLocale])
+}
+
+// normalizeValue converts value edited outside of resources (e.g. in csv) to android form: real newlines,
+// tabs and no-break spaces become \n, \t and \u00A0, leading and trailing whitespace (dropped by android anyway)
+// is removed, xml special characters and quotes are escaped (see resourceValue); value equal to one of originals
+// (or to its editable form) is returned as the original, so round trip without changes is byte-identical
+func normalizeValue(value string, originals ...string) string {
+	for _, o := range originals {
+		if value == o || value == editableValue(o) {
+			return o
+		}
+	}
+	value = strings.Trim(strings.Replace(value, "\r\n", "\n", -1), " \t\r\n")
+	return resourceValue(strings.NewReplacer("\n", `\n`, "\t", `\t`, "\u00a0", `\u00A0`).Replace(value))
+}
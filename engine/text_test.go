@@ -0,0 +1,47 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAndroidValueQuotesSpaces(t *testing.T) {
+	tests := []struct {
+		text, want string
+	}{
+		{"hello", "hello"},
+		{"  hello  ", `"  hello  "`},
+		{"hello ", `"hello "`},
+		{" l'app", `" l\'app"`},
+	}
+	for _, tt := range tests {
+		got := AndroidValue(tt.text)
+		if got != tt.want {
+			t.Errorf("AndroidValue(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+		if text := PlainText(got); text != tt.text {
+			t.Errorf("PlainText(%q) = %q, want %q", got, text, tt.text)
+		}
+	}
+}
+
+func TestQuotedSpacesSurviveSave(t *testing.T) {
+	def := strings.Replace(testDefault, ">Hello<", `>"  hello  "<`, 1)
+	l := loadProject(t, map[string]string{"values/strings.xml": def, "values-de/strings.xml": testGerman})
+	if v := l.Strings()["hello"].Values[defLocale]; v != `"  hello  "` {
+		t.Errorf("loaded value is %q", v)
+	}
+	input := "id,def,de\nhello,,\"\"\"  hallo  \"\"\"\n"
+	if err := l.ImportR(strings.NewReader(input)); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.SetWriteDefault(true).Save(); err != nil {
+		t.Fatal(err)
+	}
+	if got := readProjectFile(t, l, "values/strings.xml"); got != def {
+		t.Errorf("default file is changed:\n%s", got)
+	}
+	if got := readProjectFile(t, l, "values-de/strings.xml"); !strings.Contains(got, `<string name="hello">"  hallo  "</string>`) {
+		t.Errorf("quoted value is not saved:\n%s", got)
+	}
+}
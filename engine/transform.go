@@ -0,0 +1,27 @@
+package engine
+
+//TransformFunc returns value to be written instead of value of string for locale (see SetTransform);
+//values are raw xml content of elements
+type TransformFunc func(name, locale, value string) string
+
+//SetTransform sets function applied by Save to values of translatable strings written to locale files
+//(e.g. to wrap them in brackets for layout testing); values kept in engine and default resources file
+//are not changed; nil removes transformation
+func (l *Localizer) SetTransform(fn TransformFunc) *Localizer {
+	l.transform = fn
+	return l
+}
+
+//SetTransformExports sets whether transformation set by SetTransform is applied to values of csv exports too
+func (l *Localizer) SetTransformExports(transform bool) *Localizer {
+	l.transformExports = transform
+	return l
+}
+
+// transformed returns value transformed by function set by SetTransform (value itself if there is no one)
+func (l *Localizer) transformed(name, loc, value string) string {
+	if l.transform == nil {
+		return value
+	}
+	return l.transform(name, loc, value)
+}
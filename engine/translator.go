@@ -0,0 +1,113 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+//StatusMachine is status of values filled by Translator
+const StatusMachine = "machine"
+
+//Translator translates text from one language to another (languages are BCP 47 tags, e.g. pt-BR)
+type Translator interface {
+	Translate(text, from, to string) (string, error)
+}
+
+//SetDefaultLanguage sets language of default locale passed to Translator
+//(language of default values dir if it is qualified one, e.g. values-en, en otherwise)
+func (l *Localizer) SetDefaultLanguage(lang string) *Localizer {
+	l.defLanguage = lang
+	return l
+}
+
+func (l *Localizer) defaultLanguage() string {
+	if l.defLanguage == "" {
+		if l.defaultDir != valuesDir && l.defaultDir != "" {
+			return LanguageTag(strings.TrimPrefix(l.defaultDir, valuesDir+"-"))
+		}
+		return "en"
+	}
+	return l.defLanguage
+}
+
+//FillMissing translates default values of translatable strings that have no (or empty) values for given locales
+//(all the engine's locales if none given; see String.IsTranslated) with t; filled values get StatusMachine status.
+//Text is passed to t as plain text (see PlainText), so markup of such values is lost.
+//Returns count of filled values
+func (l *Localizer) FillMissing(t Translator, locales ...string) (int, error) {
+	if l.err != nil {
+		return 0, l.err
+	}
+	if len(locales) == 0 {
+		locales = l.Locales[1:]
+	}
+	from := l.defaultLanguage()
+	filled := 0
+	for _, loc := range locales {
+		l.addLocale(loc)
+		for _, n := range l.sortedNames() {
+			s := l.strings[n]
+			if !l.isTranslatable(s) || s.IsTranslated(loc) || s.Values[defLocale] == "" {
+				continue
+			}
+			tr, err := t.Translate(PlainText(s.Values[defLocale]), from, LanguageTag(loc))
+			if err != nil {
+				return filled, fmt.Errorf("%s: translation to '%s' failed: %v", n, loc, err)
+			}
+			s.Values[loc] = AndroidValue(tr)
+			s.SetStatus(loc, StatusMachine)
+			filled++
+		}
+	}
+	return filled, nil
+}
+
+//FakeTranslator is deterministic Translator for tests: it returns text prefixed with [to]
+type FakeTranslator struct{}
+
+//Translate returns "[to] text"
+func (FakeTranslator) Translate(text, from, to string) (string, error) {
+	return fmt.Sprintf("[%s] %s", to, text), nil
+}
+
+//RetryTranslator wraps Translator limiting rate of calls and retrying failed ones
+type RetryTranslator struct {
+	Translator Translator
+	//Retries is count of additional attempts after failed call
+	Retries int
+	//RetryDelay is delay before the first retry; it is doubled for every next one
+	RetryDelay time.Duration
+	//MinInterval is min interval between calls
+	MinInterval time.Duration
+
+	mu   sync.Mutex
+	last time.Time
+}
+
+//Translate calls wrapped Translator respecting MinInterval and retrying on errors
+func (rt *RetryTranslator) Translate(text, from, to string) (res string, err error) {
+	delay := rt.RetryDelay
+	for attempt := 0; attempt <= rt.Retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(delay)
+			delay *= 2
+		}
+		rt.wait()
+		res, err = rt.Translator.Translate(text, from, to)
+		if err == nil {
+			return
+		}
+	}
+	return
+}
+
+func (rt *RetryTranslator) wait() {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	if d := rt.MinInterval - time.Since(rt.last); d > 0 {
+		time.Sleep(d)
+	}
+	rt.last = time.Now()
+}
@@ -0,0 +1,91 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFillMissing(t *testing.T) {
+	l := savedProject(t)
+	filled, err := l.FillMissing(FakeTranslator{}, "de", "fr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filled != 3 {
+		t.Errorf("%d values are filled instead of 3", filled)
+	}
+	want := map[string]map[string]string{
+		"hello": {"de": "Hallo", "fr": "[fr] Hello"},
+		"bye":   {"de": "[de] Bye", "fr": "[fr] Bye"},
+	}
+	for n, values := range want {
+		s := l.Strings()[n]
+		for loc, v := range values {
+			if s.Values[loc] != v {
+				t.Errorf("%s: value for %s is %q instead of %q", n, loc, s.Values[loc], v)
+			}
+		}
+	}
+	if st := l.Strings()["bye"].Status["de"]; st != StatusMachine {
+		t.Errorf("status is %q", st)
+	}
+	if st := l.Strings()["hello"].Status["de"]; st != "" {
+		t.Errorf("status of human translation is %q", st)
+	}
+	if _, ok := l.Strings()["app_name"].Values["fr"]; ok {
+		t.Error("non-translatable string is translated")
+	}
+}
+
+func TestFillMissingKeepsIdenticalTranslation(t *testing.T) {
+	// human translation equal to default value is not replaced
+	l := loadProject(t, map[string]string{"values/strings.xml": testDefault, "values-de/strings.xml": `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <string name="hello">Hallo</string>
+    <string name="bye">Bye</string>
+</resources>
+`})
+	filled, err := l.FillMissing(FakeTranslator{}, "de")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filled != 0 {
+		t.Errorf("%d values are filled", filled)
+	}
+	if s := l.Strings()["bye"]; s.Values["de"] != "Bye" || s.Status["de"] != "" {
+		t.Errorf("value is %q (%s)", s.Values["de"], s.Status["de"])
+	}
+}
+
+type failingTranslator struct {
+	failures int
+	calls    int
+}
+
+func (t *failingTranslator) Translate(text, from, to string) (string, error) {
+	t.calls++
+	if t.calls <= t.failures {
+		return "", errors.New("unavailable")
+	}
+	return FakeTranslator{}.Translate(text, from, to)
+}
+
+func TestRetryTranslator(t *testing.T) {
+	tr := &failingTranslator{failures: 2}
+	res, err := (&RetryTranslator{Translator: tr, Retries: 2}).Translate("Hello", "en", "de")
+	if err != nil || res != "[de] Hello" || tr.calls != 3 {
+		t.Errorf("result is %q, %v after %d calls", res, err, tr.calls)
+	}
+	tr = &failingTranslator{failures: 5}
+	if _, err = (&RetryTranslator{Translator: tr, Retries: 1}).Translate("Hello", "en", "de"); err == nil || tr.calls != 2 {
+		t.Errorf("error is %v after %d calls", err, tr.calls)
+	}
+}
+
+func TestFillMissingStopsOnError(t *testing.T) {
+	l := loadProject(t, map[string]string{"values/strings.xml": testDefault})
+	filled, err := l.FillMissing(&failingTranslator{failures: 1}, "fr")
+	if err == nil || filled != 0 {
+		t.Errorf("filled %d, error %v", filled, err)
+	}
+}
@@ -0,0 +1,35 @@
+package engine
+
+import "fmt"
+
+//UntranslatedPolicy defines what Save writes to locale files for strings without translation
+type UntranslatedPolicy int
+
+const (
+	//UntranslatedCopy writes default value (default)
+	UntranslatedCopy UntranslatedPolicy = iota
+	//UntranslatedOmit leaves string out of locale file, so android falls back to default resources
+	//(and lint reports missing translation)
+	UntranslatedOmit
+	//UntranslatedEmpty writes empty element as marker of missing translation
+	UntranslatedEmpty
+)
+
+//ParseUntranslatedPolicy returns UntranslatedPolicy by its name: copy, omit or empty
+func ParseUntranslatedPolicy(name string) (UntranslatedPolicy, error) {
+	switch name {
+	case "copy", "":
+		return UntranslatedCopy, nil
+	case "omit":
+		return UntranslatedOmit, nil
+	case "empty":
+		return UntranslatedEmpty, nil
+	}
+	return UntranslatedCopy, fmt.Errorf("invalid untranslated policy '%s': should be copy, omit or empty", name)
+}
+
+//SetUntranslatedPolicy sets what Save writes for strings without value for locale (and its fallback locales)
+func (l *Localizer) SetUntranslatedPolicy(policy UntranslatedPolicy) *Localizer {
+	l.untranslated = policy
+	return l
+}
@@ -0,0 +1,115 @@
+package engine
+
+import (
+	"io/fs"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	stringRefRegexp = regexp.MustCompile(`(?:R\.(?:string|plurals|array)\.|@(?:string|plurals|array)/)([A-Za-z0-9_.]+)`)
+	sourceExts      = map[string]bool{".kt": true, ".java": true, ".xml": true}
+	skippedDirs     = map[string]bool{"build": true, ".git": true, ".gradle": true, ".idea": true}
+)
+
+//SetUnusedWhitelist sets name patterns (path.Match globs, e.g. "debug_*") of strings
+//that should never be reported as unused (e.g. names built dynamically at runtime)
+func (l *Localizer) SetUnusedWhitelist(patterns ...string) *Localizer {
+	l.unusedWhitelist = patterns
+	return l
+}
+
+//SetUnusedPrefixes adds name prefixes (e.g. "debug_") of strings that should never be reported as unused
+//to whitelist set by SetUnusedWhitelist
+func (l *Localizer) SetUnusedPrefixes(prefixes ...string) *Localizer {
+	for _, p := range prefixes {
+		l.unusedWhitelist = append(l.unusedWhitelist, p+"*")
+	}
+	return l
+}
+
+//Unused returns sorted names of translatable strings not referenced from sources in the parent
+//of resources dir (see FindUnused); error is kept as engine's error (see Err)
+func (l *Localizer) Unused() []string {
+	unused, err := l.FindUnused()
+	if err != nil {
+		l.err = err
+	}
+	return unused
+}
+
+//FindUnused scans .kt, .java and .xml files in srcDirs (or in the parent of resources dir
+//if no dirs given) for R.string.<name> and @string/<name> references (R.plurals, R.array and @plurals, @array
+//for items of plurals and string arrays)
+//and returns sorted names of translatable strings that are not referenced
+func (l *Localizer) FindUnused(srcDirs ...string) ([]string, error) {
+	if l.err != nil {
+		return nil, l.err
+	}
+	if len(srcDirs) == 0 {
+		srcDirs = []string{filepath.Dir(l.ResourcesDir)}
+	}
+	used := map[string]bool{}
+	for _, dir := range srcDirs {
+		err := fs.WalkDir(l.fsys, l.fsName(dir), func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if skippedDirs[d.Name()] {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if !sourceExts[path.Ext(p)] {
+				return nil
+			}
+			content, err := l.readFile(p)
+			if err != nil {
+				return err
+			}
+			for _, m := range stringRefRegexp.FindAllSubmatch(content, -1) {
+				used[rName(string(m[1]))] = true
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	unused := []string{}
+	for n, s := range l.strings {
+		// items of plurals and string arrays are used with their resources
+		res, _, _ := splitItemName(n)
+		if s.Translatable && !used[rName(res)] && !matchesAny(n, l.unusedWhitelist) {
+			unused = append(unused, n)
+		}
+	}
+	sort.Strings(unused)
+	return unused, nil
+}
+
+//Remove removes strings with given names from engine, so Save will not write them to locale files
+func (l *Localizer) Remove(names ...string) *Localizer {
+	for _, n := range names {
+		delete(l.strings, n)
+	}
+	return l
+}
+
+// aapt converts dots in resource names to underscores for R class
+func rName(name string) string {
+	return strings.Replace(name, ".", "_", -1)
+}
+
+func matchesAny(name string, patterns []string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
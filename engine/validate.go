@@ -0,0 +1,59 @@
+package engine
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+//LengthError describes value that is longer than allowed
+type LengthError struct {
+	Name      string
+	Locale    string
+	Length    int
+	DefLength int
+	Limit     int
+}
+
+func (e *LengthError) Error() string {
+	if e.Limit > 0 {
+		return fmt.Sprintf("%s: value for '%s' is %d characters long, limit is %d (default is %d)", e.Name, e.Locale, e.Length, e.Limit, e.DefLength)
+	}
+	return fmt.Sprintf("%s: value for '%s' is %d characters long, default is %d (%.2fx)", e.Name, e.Locale, e.Length, e.DefLength, float64(e.Length)/float64(e.DefLength))
+}
+
+//SetMaxExpansion sets max allowed ratio of translated value length to default value length
+//used by ValidateLengths (0 means no check)
+func (l *Localizer) SetMaxExpansion(ratio float64) *Localizer {
+	l.maxExpansion = ratio
+	return l
+}
+
+//ValidateLengths checks translated values of translatable strings against limits (max length by string name)
+//and max expansion ratio (see SetMaxExpansion) and returns *LengthError for every violation
+func (l *Localizer) ValidateLengths(limits map[string]int) []error {
+	if l.err != nil {
+		return []error{l.err}
+	}
+	var errs []error
+	for _, n := range l.sortedNames() {
+		s := l.strings[n]
+		if !l.isTranslatable(s) {
+			continue
+		}
+		defLen := utf8.RuneCountInString(s.Values[defLocale])
+		limit := limits[n]
+		for _, loc := range l.Locales[1:] {
+			v, ok := s.Values[loc]
+			if !ok {
+				continue
+			}
+			vLen := utf8.RuneCountInString(v)
+			if limit > 0 && vLen > limit {
+				errs = append(errs, &LengthError{Name: n, Locale: loc, Length: vLen, DefLength: defLen, Limit: limit})
+			} else if l.maxExpansion > 0 && defLen > 0 && float64(vLen) > float64(defLen)*l.maxExpansion {
+				errs = append(errs, &LengthError{Name: n, Locale: loc, Length: vLen, DefLength: defLen})
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,38 @@
+package engine
+
+import (
+	"regexp"
+	"strings"
+)
+
+// whitespaceRegexp finds runs of whitespace collapsed by normalization (escapes like \n are not whitespace)
+var whitespaceRegexp = regexp.MustCompile(`\s+`)
+
+//SetNormalizeWhitespace sets whether Load trims values and collapses runs of whitespace (e.g. line breaks
+//of long values wrapped in xml) into single spaces like android does for unquoted values, so that exports
+//do not carry them to translators; values of strings matching patterns set by SetPreserveWhitespace,
+//quoted values and values with CDATA sections are kept exactly as they are
+func (l *Localizer) SetNormalizeWhitespace(normalize bool) *Localizer {
+	l.normalizeWhitespace = normalize
+	return l
+}
+
+//SetPreserveWhitespace sets name patterns (path.Match globs, e.g. "legal_*") of strings which whitespace
+//is never normalized (see SetNormalizeWhitespace)
+func (l *Localizer) SetPreserveWhitespace(patterns ...string) *Localizer {
+	l.preserveWhitespace = patterns
+	return l
+}
+
+// normalizedValue returns value of string read by Load with whitespace normalized if it is enabled
+func (l *Localizer) normalizedValue(name, value string) string {
+	if !l.normalizeWhitespace || matchesAny(name, l.preserveWhitespace) {
+		return value
+	}
+	trimmed := strings.TrimSpace(value)
+	if strings.HasPrefix(trimmed, `"`) || strings.Contains(value, "<![CDATA[") {
+		// android keeps whitespace of quoted values, CDATA sections are kept as they are
+		return value
+	}
+	return whitespaceRegexp.ReplaceAllString(trimmed, " ")
+}
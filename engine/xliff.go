@@ -0,0 +1,210 @@
+package engine
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+)
+
+const (
+	xliffExt       = ".xlf"
+	xliffNamespace = "urn:oasis:names:tc:xliff:document:1.2"
+	xliffVersion   = "1.2"
+	// xliff states of values
+	xliffNeedsTranslation = "needs-translation"
+	xliffTranslated       = "translated"
+	xliffNeedsReview      = "needs-review-translation"
+	xliffFinal            = "final"
+)
+
+type xliffDoc struct {
+	XMLName xml.Name  `xml:"xliff"`
+	Xmlns   string    `xml:"xmlns,attr,omitempty"`
+	Version string    `xml:"version,attr"`
+	File    xliffFile `xml:"file"`
+}
+
+type xliffFile struct {
+	Original       string      `xml:"original,attr"`
+	SourceLanguage string      `xml:"source-language,attr"`
+	TargetLanguage string      `xml:"target-language,attr"`
+	Datatype       string      `xml:"datatype,attr"`
+	Units          []xliffUnit `xml:"body>trans-unit"`
+}
+
+type xliffUnit struct {
+	ID     string      `xml:"id,attr"`
+	Source string      `xml:"source"`
+	Target xliffTarget `xml:"target"`
+	Note   string      `xml:"note,omitempty"`
+}
+
+type xliffTarget struct {
+	State     string `xml:"state,attr,omitempty"`
+	Qualifier string `xml:"state-qualifier,attr,omitempty"`
+	Text      string `xml:",chardata"`
+}
+
+//ExportXLIFF writes XLIFF 1.2 file <locale>.xlf (e.g. pt-BR.xlf) to dir for every non-default locale;
+//sources and targets are text keeping markup tags (<b>, <xliff:g>) escaped, targets that are not changed are not
+//imported back;
+//statuses of values become state attributes of targets: translated, final (StatusApproved),
+//needs-review-translation (StatusNeedsReview, StatusMachine and StatusMemory with mt-suggestion
+//and tm-suggestion qualifiers) and needs-translation for missing values
+func (l *Localizer) ExportXLIFF(dir string) error {
+	if l.err != nil {
+		return l.err
+	}
+	err := l.mkdirAllOf(dir)
+	if err != nil {
+		return err
+	}
+	for _, loc := range l.Locales[1:] {
+		err = l.createFile(filepath.Join(dir, LanguageTag(loc)+xliffExt), func(w io.Writer) error {
+			return l.writeXLIFF(w, loc)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//ImportXLIFF imports values and their statuses (see ExportXLIFF) from .xlf and .xliff files in dir;
+//locale of file is taken from target-language attribute, targets with needs-translation state are skipped
+func (l *Localizer) ImportXLIFF(dir string) error {
+	if l.err != nil {
+		return l.err
+	}
+	var files []string
+	for _, ext := range []string{xliffExt, ".xliff"} {
+		matches, err := l.glob(filepath.Join(dir, "*"+ext))
+		if err != nil {
+			return err
+		}
+		files = append(files, matches...)
+	}
+	sort.Strings(files)
+	for _, fileName := range files {
+		if err := l.importXLIFFFile(fileName); err != nil {
+			return &FileError{FileName: fileName, Err: err}
+		}
+	}
+	return nil
+}
+
+func (l *Localizer) writeXLIFF(w io.Writer, loc string) error {
+	doc := xliffDoc{Xmlns: xliffNamespace, Version: xliffVersion,
+		File: xliffFile{Original: l.stringsFileName(), SourceLanguage: l.defaultLanguage(),
+			TargetLanguage: LanguageTag(loc), Datatype: "plaintext", Units: []xliffUnit{}}}
+	for _, n := range l.rowNames() {
+		s := l.strings[n]
+		if !l.isTranslatable(s) {
+			continue
+		}
+		u := xliffUnit{ID: n, Source: markupText(s.Values[defLocale]), Note: s.Comment}
+		if v := s.Values[loc]; v != "" {
+			u.Target.Text = markupText(v)
+			u.Target.State, u.Target.Qualifier = xliffState(s.Status[loc])
+		} else {
+			u.Target.State = xliffNeedsTranslation
+		}
+		doc.File.Units = append(doc.File.Units, u)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", xmlIndent)
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func (l *Localizer) importXLIFFFile(fileName string) error {
+	f, err := l.openFile(fileName)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	doc := xliffDoc{}
+	if err = xml.NewDecoder(f).Decode(&doc); err != nil {
+		return fmt.Errorf("invalid xliff format: %v", err)
+	}
+	if doc.File.TargetLanguage == "" {
+		return fmt.Errorf("target-language of file is not set")
+	}
+	loc, err := NormalizeLocale(doc.File.TargetLanguage)
+	if err != nil {
+		return err
+	}
+	type update struct {
+		s      *String
+		value  string
+		status string
+	}
+	var updates []update
+	for _, u := range doc.File.Units {
+		if l.isExcluded(u.ID) {
+			continue
+		}
+		s, ok := l.strings[u.ID]
+		if !ok {
+			return &UnknownKeyError{Name: u.ID, Source: "xliff"}
+		}
+		if s.IsReference() || !s.Translatable || u.Target.Text == "" || u.Target.State == xliffNeedsTranslation {
+			continue
+		}
+		value := s.Values[loc]
+		if u.Target.Text != markupText(value) {
+			// unchanged targets keep their values as they are (e.g. with escapes or markup of other forms)
+			value = l.importedNewlines(markupValue(u.Target.Text))
+		}
+		updates = append(updates, update{s, value, xliffStatus(u.Target.State, u.Target.Qualifier)})
+	}
+	l.addLocale(loc)
+	for _, u := range updates {
+		if l.keepsReference(u.s, loc, u.value) {
+			continue
+		}
+		u.s.Values[loc] = u.value
+		u.s.SetStatus(loc, u.status)
+	}
+	return nil
+}
+
+// xliffState returns xliff state and state qualifier of value with status
+func xliffState(status string) (string, string) {
+	switch status {
+	case StatusApproved:
+		return xliffFinal, ""
+	case StatusNeedsReview:
+		return xliffNeedsReview, ""
+	case StatusMachine:
+		return xliffNeedsReview, "mt-suggestion"
+	case StatusMemory:
+		return xliffNeedsReview, "tm-suggestion"
+	}
+	return xliffTranslated, ""
+}
+
+// xliffStatus returns status of value by xliff state and state qualifier
+func xliffStatus(state, qualifier string) string {
+	switch state {
+	case xliffFinal, "signed-off":
+		return StatusApproved
+	case xliffNeedsReview, "needs-review-l10n", "needs-review-adaptation", "needs-l10n", "needs-adaptation":
+		switch qualifier {
+		case "mt-suggestion":
+			return StatusMachine
+		case "tm-suggestion", "exact-match", "fuzzy-match", "leveraged-tm":
+			return StatusMemory
+		}
+		return StatusNeedsReview
+	}
+	return ""
+}
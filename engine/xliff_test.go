@@ -0,0 +1,93 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	testMarkupDefault = `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <string name="greeting"><b>Hi</b> <xliff:g id="user">%1$s</xliff:g></string>
+    <string name="quote">It\'s &lt;fine&gt;</string>
+</resources>
+`
+	testMarkupFrench = `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <string name="greeting"><b>Salut</b> <xliff:g id="user">%1$s</xliff:g></string>
+    <string name="quote">C\'est &lt;bon&gt;</string>
+</resources>
+`
+)
+
+// replaceInFile replaces old with new in file failing the test if there is no old
+func replaceInFile(t *testing.T, fileName, old, new string) {
+	t.Helper()
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), old) {
+		t.Fatalf("%s does not contain %q:\n%s", fileName, old, content)
+	}
+	if err = os.WriteFile(fileName, []byte(strings.Replace(string(content), old, new, 1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestXLIFFKeepsMarkup(t *testing.T) {
+	l := loadProject(t, map[string]string{"values/strings.xml": testMarkupDefault, "values-fr/strings.xml": testMarkupFrench})
+	dir := t.TempDir()
+	if err := l.ExportXLIFF(dir); err != nil {
+		t.Fatal(err)
+	}
+	fileName := filepath.Join(dir, "fr.xlf")
+	content, _ := os.ReadFile(fileName)
+	if !strings.Contains(string(content), `&lt;b&gt;Salut&lt;/b&gt; &lt;xliff:g id=&#34;user&#34;&gt;%1$s&lt;/xliff:g&gt;`) {
+		t.Errorf("markup is not exported:\n%s", content)
+	}
+	replaceInFile(t, fileName, ">C&#39;est &lt;bon&gt;<", ` state="final">C&#39;est &lt;bon&gt;<`)
+	if err := l.ImportXLIFF(dir); err != nil {
+		t.Fatal(err)
+	}
+	fr := l.Strings()["greeting"].Values["fr"]
+	if fr != `<b>Salut</b> <xliff:g id="user">%1$s</xliff:g>` {
+		t.Errorf("unchanged value became %q", fr)
+	}
+	quote := l.Strings()["quote"]
+	if quote.Values["fr"] != `C\'est &lt;bon&gt;` || quote.Status["fr"] != StatusApproved {
+		t.Errorf("quote is %q (%s)", quote.Values["fr"], quote.Status["fr"])
+	}
+
+	replaceInFile(t, fileName, "Salut", "Coucou")
+	if err := l.ImportXLIFF(dir); err != nil {
+		t.Fatal(err)
+	}
+	fr = l.Strings()["greeting"].Values["fr"]
+	if fr != `<b>Coucou</b> <xliff:g id="user">%1$s</xliff:g>` {
+		t.Errorf("changed value is imported as %q", fr)
+	}
+}
+
+func TestMarkupText(t *testing.T) {
+	for value, text := range map[string]string{
+		`<b>Hi</b> <xliff:g id="user">%1$s</xliff:g>`: `<b>Hi</b> <xliff:g id="user">%1$s</xliff:g>`,
+		`a &lt;b&gt; c \"d\" <unknown>e</unknown>`:    `a <b> c "d" e`,
+		`" spaced <i>text</i> "`:                      ` spaced <i>text</i> `,
+	} {
+		if got := markupText(value); got != text {
+			t.Errorf("text of %q is %q instead of %q", value, got, text)
+		}
+	}
+	for text, value := range map[string]string{
+		`<b>l'app</b> & <xliff:g id="n">%1$d</xliff:g>`: `<b>l\'app</b> &amp; <xliff:g id="n">%1$d</xliff:g>`,
+		`1 < 2`:     `1 &lt; 2`,
+		` <i>x</i>`: `" <i>x</i>"`,
+	} {
+		if got := markupValue(text); got != value {
+			t.Errorf("value of %q is %q instead of %q", text, got, value)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/xml"
+)
+
+// namespacePrefixes returns prefixes of namespaces declared by attributes (e.g. xmlns:tools) by their urls
+func namespacePrefixes(attrs []xml.Attr) map[string]string {
+	res := map[string]string{}
+	for _, a := range attrs {
+		if a.Name.Space == "xmlns" {
+			res[a.Value] = a.Name.Local
+		}
+	}
+	return res
+}
+
+// prefixedAttrs returns attributes decoded with namespace urls with names written as in file (tools:ignore),
+// so they are encoded exactly as they were read
+func prefixedAttrs(attrs []xml.Attr, prefixes map[string]string) []xml.Attr {
+	var res []xml.Attr
+	for _, a := range attrs {
+		if a.Name.Space != "" {
+			prefix, ok := prefixes[a.Name.Space]
+			if !ok {
+				// namespace is not declared: decoder keeps prefix as it is
+				prefix = a.Name.Space
+			}
+			a.Name = xml.Name{Local: prefix + ":" + a.Name.Local}
+		}
+		res = append(res, a)
+	}
+	return res
+}
+
+// writeStartTag writes start tag of element with attributes (e.g. <resources xmlns:tools="...">)
+func writeStartTag(out *bytes.Buffer, name string, attrs []xml.Attr) {
+	out.WriteString("<" + name)
+	for _, a := range attrs {
+		out.WriteString(" " + a.Name.Local + `="`)
+		xml.EscapeText(out, []byte(a.Value))
+		out.WriteString(`"`)
+	}
+	out.WriteString(">")
+}
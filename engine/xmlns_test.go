@@ -0,0 +1,44 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSaveKeepsNamespaces(t *testing.T) {
+	def := `<?xml version="1.0" encoding="utf-8"?>
+<resources xmlns:tools="http://schemas.android.com/tools" xmlns:xliff="urn:oasis:names:tc:xliff:document:1.2">
+    <string name="app_name" translatable="false">Test</string>
+    <string name="hello" tools:ignore="MissingTranslation">Hello <xliff:g id="user">%1$s</xliff:g></string>
+    <string name="bye">Bye</string>
+</resources>
+`
+	de := `<?xml version="1.0" encoding="utf-8"?>
+<resources xmlns:tools="http://schemas.android.com/tools" tools:ignore="ExtraTranslation">
+    <string name="bye" tools:ignore="Typos">Tschüss</string>
+</resources>
+`
+	l := loadProject(t, map[string]string{"values/strings.xml": def, "values-de/strings.xml": de})
+	if err := l.ImportR(strings.NewReader("id,def,de\nhello,,Hallo %1$s\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.SetWriteDefault(true).Save(); err != nil {
+		t.Fatal(err)
+	}
+	if got := readProjectFile(t, l, "values/strings.xml"); got != def {
+		t.Errorf("default file is changed:\n%s", got)
+	}
+	got := readProjectFile(t, l, "values-de/strings.xml")
+	for _, kept := range []string{
+		`<resources xmlns:tools="http://schemas.android.com/tools" tools:ignore="ExtraTranslation">`,
+		`<string name="bye" tools:ignore="Typos">Tschüss</string>`,
+	} {
+		if !strings.Contains(got, kept) {
+			t.Errorf("%s is lost:\n%s", kept, got)
+		}
+	}
+	// saved file must load again
+	if err := New(l.ResourcesDir).Load().Err(); err != nil {
+		t.Error(err)
+	}
+}
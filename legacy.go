@@ -0,0 +1,86 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/vc2402/localizer/engine"
+)
+
+// legacy runs tool with flags used before commands were introduced
+//
+// Deprecated: will be removed in the next release
+func legacy(args []string) error {
+	fs := flag.NewFlagSet("main", flag.ExitOnError)
+	fs.Usage = func() {
+		fs.Output().Write([]byte(fmt.Sprintf("Usage: %s -export|-export-per-locale|-template|-import|-unused|-roundtrip [other-flags] androidProjectPath\n", filepath.Base(os.Args[0]))))
+		fs.PrintDefaults()
+	}
+	expF := fs.String("export", "", "`path` to csv-file to export values to (- for stdout)")
+	perLocF := fs.String("export-per-locale", "", "`path` to dir to export <locale>.csv file with id, def and locale columns for every locale to")
+	templF := fs.String("template", "", "`path` to csv-file to export default values with empty locale columns to")
+	impF := fs.String("import", "", "`path` to csv-file to import values from (- for stdin)")
+	unusedF := fs.Bool("unused", false, "print translatable strings that are not referenced from sources")
+	pruneF := fs.Bool("prune-unused", false, "with -unused: remove unused strings from locale files on save")
+	srcF := fs.String("src", "", "coma-separated `dirs` to scan for string references (default: parent of resources dir)")
+	keepF := fs.String("keep", "", "coma-separated name `patterns` (e.g. debug_*) never reported as unused")
+	rtF := fs.Bool("roundtrip", false, "check that default resources survive load and save without changes")
+	maxExpF := fs.Float64("max-expansion", 0, "with -import: warn about translations longer than `ratio` * default value length")
+	localesF := fs.String("locales", "", "coma-separated names of required locales in addition to found in project (e.g. de,fr,pt-BR)")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errUsage
+	}
+	if projects, ok, err := batchProjects(fs.Args()); ok {
+		if err != nil {
+			return err
+		}
+		return runBatch(legacy, args[:len(args)-fs.NArg()], projects)
+	}
+	locales, err := parseLocales(*localesF)
+	if err != nil {
+		fs.Output().Write([]byte(fmt.Sprintln(err)))
+		fs.Usage()
+		return errUsage
+	}
+	ap := fs.Arg(0)
+	eng := engine.New(ap, locales...).Load()
+
+	if *expF != "" {
+		err = exportTo(eng, *expF, "csv")
+	} else if *perLocF != "" {
+		err = eng.ExportPerLocale(*perLocF)
+	} else if *templF != "" {
+		err = eng.ExportTemplate(*templF)
+	} else if *impF != "" {
+		err = importFrom(eng, *impF, "csv")
+		if err == nil {
+			printWarnings(eng.SetMaxExpansion(*maxExpF).ValidateLengths(nil))
+			err = save(eng)
+		}
+	} else if *rtF {
+		err = eng.CheckRoundTrip()
+		if err == nil {
+			fmt.Println("ok")
+		}
+	} else if *unusedF {
+		var unused []string
+		unused, err = eng.SetUnusedWhitelist(splitList(*keepF)...).FindUnused(splitList(*srcF)...)
+		if err == nil {
+			for _, n := range unused {
+				fmt.Println(n)
+			}
+			if *pruneF {
+				err = save(eng.Remove(unused...))
+			}
+		}
+	} else {
+		fs.Usage()
+		return errUsage
+	}
+	return err
+}
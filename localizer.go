@@ -1,44 +1,420 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/vc2402/localizer/engine"
 )
 
+type command struct {
+	name  string
+	descr string
+	run   func(args []string) error
+}
+
+var commands = []command{
+	{"export", "export values to file (csv, tsv, json, ndjson or model) or to dir (apple, arb, csv, properties, xliff)", exportCmd},
+	{"import", "import values from file (or files of dir) and save them to locale resources", importCmd},
+	{"sync", "merge csv file and resources in both directions", syncCmd},
+	{"merge", "merge csv files by ids reporting conflicting values", mergeCmd},
+	{"normalize", "print diff of (or rewrite with -write) default resources file with sorted and consistently formatted strings", normalizeCmd},
+	{"init", "create resources of new locales (given with -locales)", initCmd},
+	{"report", "print translation statistics for every locale", reportCmd},
+	{"check", "print missing translations and invalid placeholders and fail if there are any", checkCmd},
+	{"validate", "check values lengths, line breaks and resources round-trip", validateCmd},
+	{"memory", "fill missing translations from strings with the same default value", memoryCmd},
+	{"duplicates", "list strings with the same default value", duplicatesCmd},
+	{"identical", "list strings with the same value in all locales", identicalCmd},
+	{"pseudo", "generate pseudo-locale from default values", pseudoCmd},
+	{"rename", "rename strings in all the resource files", renameCmd},
+	{"unused", "print strings that are not referenced from sources", unusedCmd},
+	{"serve", "serve web page for reviewing and editing translations (saved to resources on request)", serveCmd},
+}
+
+// errUsage is returned by commands when usage was already printed
+var errUsage = errors.New("invalid arguments")
+
 func main() {
+	err := run(os.Args[1:])
+	if err == errUsage {
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
 
-	fs := flag.NewFlagSet("main", flag.ExitOnError)
+// run runs command given in args; errUsage is returned if args are invalid
+func run(args []string) error {
+	if len(args) > 0 && strings.HasPrefix(args[0], "-") && !isHelpFlag(args[0]) {
+		fmt.Fprintln(os.Stderr, "warning: running without command is deprecated and will be removed in the next release; see -h for commands")
+		return legacy(args)
+	}
+	if len(args) == 0 {
+		usage()
+		return errUsage
+	}
+	if isHelpFlag(args[0]) {
+		usage()
+		return nil
+	}
+	for _, c := range commands {
+		if c.name == args[0] {
+			err := c.run(args[1:])
+			if err == errBatchDone {
+				return nil
+			}
+			return err
+		}
+	}
+	fmt.Fprintf(os.Stderr, "unknown command '%s'\n", args[0])
+	usage()
+	return errUsage
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s command [flags] androidProjectPath...\nCommands:\n", filepath.Base(os.Args[0]))
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", c.name, c.descr)
+	}
+	fmt.Fprintf(os.Stderr, "Several project paths (or patterns like apps/*/) may be given: {} in flags is replaced with project name\n")
+	fmt.Fprintf(os.Stderr, "Run '%s command -h' for command flags\n", filepath.Base(os.Args[0]))
+}
+
+func isHelpFlag(arg string) bool {
+	return arg == "-h" || arg == "-help" || arg == "--help"
+}
+
+// cmdFlags contains flags common for all the commands
+type cmdFlags struct {
+	*flag.FlagSet
+	locales       *string
+	exclude       *string
+	excludeFiles  *string
+	verbose       *bool
+	strict        *bool
+	stringsFile   *string
+	module        *string
+	normalizeWS   *bool
+	canonicalLocs *bool
+	preserveWS    *string
+	backup        *string
+	noBackup      *bool
+	cleanBackups  *bool
+	review        *bool
+	writeDefault  *bool
+	indent        *string
+	pseudo        *string
+	keepNonTrans  *bool
+	backfill      *bool
+	forceRefs     *bool
+	normNewlines  *bool
+	fallback      *string
+	untranslated  *string
+	filter        *string
+	placeholders  *string
+	utf8          *bool
+	delimiter     *string
+	columns       *string
+	filterRegex   *string
+	keyPattern    *regexp.Regexp
+	parsedLocales []string
+	importLocales []string
+	backupMode    engine.BackupMode
+	style         engine.PlaceholderStyle
+	indentation   string
+	fallbacks     map[string]string
+	policy        engine.UntranslatedPolicy
+	comma         rune
+	mapping       *engine.Mapping
+}
+
+func newFlagSet(name string) *cmdFlags {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
 	fs.Usage = func() {
-		fs.Output().Write([]byte(fmt.Sprintf("Usage: %s -export|-import [other-flags] androidProjectPath\n", filepath.Base(os.Args[0]))))
+		fs.Output().Write([]byte(fmt.Sprintf("Usage: %s %s [flags] androidProjectPath\n", filepath.Base(os.Args[0]), name)))
 		fs.PrintDefaults()
 	}
-	expF := fs.String("export", "", "`path` to csv-file to export values to")
-	impF := fs.String("import", "", "`path` to csv-file to import values from")
-	// locales := fs.String("locales", "", "coma-separated names of required locales (may be defined automatically)")
-	fs.Parse(os.Args[1:])
+	locales := fs.String("locales", "", "coma-separated names of required locales in addition to found in project (e.g. de,fr,pt-BR); they go first in given order")
+	exclude := fs.String("exclude", "", "coma-separated name `patterns` (e.g. debug_*,analytics_*) of strings to leave out of processing")
+	excludeFiles := fs.String("exclude-files", "", "coma-separated name `globs` of resource files in values dir strings of which are never translated (default donottranslate.xml)")
+	stringsFile := fs.String("strings-file", "", "`name` of resource files in values dirs (default strings.xml)")
+	module := fs.String("module", "", "gradle `module` to process if resources are found in several modules (e.g. app)")
+	normalizeWS := fs.Bool("normalize-whitespace", false, "trim values and collapse runs of whitespace (e.g. line breaks of wrapped values) into single spaces")
+	preserveWS := fs.String("preserve-whitespace", "", "coma-separated name `patterns` of strings which whitespace is never normalized")
+	canonical := fs.Bool("canonical-locales", false, "order locales (csv columns, reports) by language, base language before its regional variants (es, es-rMX, es-rUS)")
+	verbose := fs.Bool("verbose", false, "print verbose messages (e.g. about ignored columns)")
+	strict := fs.Bool("strict", false, "fail on problems (strings defined twice, strings without any value on save, unknown csv columns) instead of warning")
+	return &cmdFlags{FlagSet: fs, locales: locales, exclude: exclude, excludeFiles: excludeFiles, verbose: verbose, strict: strict, stringsFile: stringsFile,
+		module: module, normalizeWS: normalizeWS, preserveWS: preserveWS, canonicalLocs: canonical}
+}
 
-	if fs.NArg() == 0 {
-		fs.Usage()
-		return
-	}
-	ap := fs.Arg(0)
-	eng := engine.New(ap).Load()
-
-	var err error
-	if *expF != "" {
-		err = eng.Export(*expF)
-	} else if *impF != "" {
-		eng.Import(*impF)
-		err = eng.Save()
-	} else {
+// saveFlags adds flags of commands that save resources
+func (fs *cmdFlags) saveFlags() {
+	fs.backup = fs.String("backup", "single", "backup `mode` for overwritten files: single (file.bak), timestamp (file.<time>.bak) or none")
+	fs.noBackup = fs.Bool("no-backup", false, "do not back up overwritten files (same as -backup none)")
+	fs.cleanBackups = fs.Bool("clean-backups", false, "remove backups left by previous runs before saving")
+	fs.writeDefault = fs.Bool("write-default", false, "import default values too and write them to default resources file")
+	fs.untranslated = fs.String("untranslated", "copy", "`policy` for strings without translation: copy (default value), omit (android falls back to default resources) or empty")
+	fs.fallback = fs.String("fallback", "", "coma-separated `locale:from` pairs of locales missing values are taken from (e.g. pt-BR:pt or ca:def; regional locales fall back to their language by default)")
+	fs.indent = fs.String("indent", "", "indentation of written locale files: count of spaces or tab (by default indentation of existing files is kept)")
+	fs.keepNonTrans = fs.Bool("keep-non-translatable", false, "keep values of non-translatable strings found in locale files (they are dropped by default)")
+	fs.pseudo = fs.String("pseudo", "", "pseudo-`locale` (e.g. en-XA) to generate from default values on save")
+	fs.backfill = fs.Bool("backfill", false, "write default values of missing translations to locale files with <!-- TODO: translate --> comment")
+}
+
+// csvFlags adds flags of commands that read or write csv files
+func (fs *cmdFlags) csvFlags() {
+	fs.delimiter = fs.String("delimiter", "", "`delimiter` of csv files: single character or tab (default ,)")
+	fs.columns = fs.String("columns", "", "`mapping` of csv columns of other tools (e.g. id=identifier,def=source_text,de=translation); other columns are ignored")
+}
+
+// placeholderFlags adds flags of commands that export or import files with placeholders (and encodings)
+func (fs *cmdFlags) placeholderFlags() {
+	fs.placeholders = fs.String("placeholders", "printf", "`style` of placeholders in json and ndjson files: printf (%1$s) or named ({arg1})")
+	fs.utf8 = fs.Bool("utf8", false, "write and read properties files in UTF-8 (ISO-8859-1 with \\uXXXX escapes by default)")
+}
+
+// reviewFlags adds flags of commands that count translated strings
+func (fs *cmdFlags) reviewFlags() {
+	fs.review = fs.Bool("review-incomplete", false, "count translations that need review (machine, memory, needs-review) as missing")
+}
+
+// filterFlags adds flags of commands that may be limited to some of the strings
+func (fs *cmdFlags) filterFlags() {
+	fs.filter = fs.String("filter", "", "process only strings which names start with `prefix` (e.g. checkout_)")
+	fs.filterRegex = fs.String("filter-regex", "", "process only strings which names match `regexp`")
+}
+
+// load parses args and loads project given as the only positional argument
+func (fs *cmdFlags) load(args []string) (*engine.Localizer, error) {
+	fs.Parse(args)
+	if err := fs.batch(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() != 1 {
 		fs.Usage()
+		return nil, errUsage
+	}
+	locales, err := parseLocales(*fs.locales)
+	if err == nil && fs.backup != nil {
+		fs.backupMode, err = engine.ParseBackupMode(*fs.backup)
+		if *fs.noBackup {
+			fs.backupMode = engine.BackupNone
+		}
+	}
+	if err == nil && fs.delimiter != nil && *fs.delimiter != "" {
+		fs.comma, err = parseDelimiter(*fs.delimiter)
+	}
+	if err == nil && fs.columns != nil && *fs.columns != "" {
+		fs.mapping, err = engine.ParseMapping(*fs.columns)
+	}
+	if err == nil && fs.untranslated != nil {
+		fs.policy, err = engine.ParseUntranslatedPolicy(*fs.untranslated)
+	}
+	if err == nil && fs.fallback != nil {
+		fs.fallbacks, err = parseFallbacks(*fs.fallback)
+	}
+	if err == nil && fs.indent != nil {
+		fs.indentation, err = parseIndent(*fs.indent)
+	}
+	if err == nil && fs.pseudo != nil && *fs.pseudo != "" {
+		*fs.pseudo, err = engine.NormalizeLocale(*fs.pseudo)
+	}
+	if err == nil && fs.placeholders != nil {
+		fs.style, err = engine.ParsePlaceholderStyle(*fs.placeholders)
+	}
+	if err == nil && fs.filterRegex != nil && *fs.filterRegex != "" {
+		fs.keyPattern, err = regexp.Compile(*fs.filterRegex)
 	}
 	if err != nil {
 		fs.Output().Write([]byte(fmt.Sprintln(err)))
-		return
+		fs.Usage()
+		return nil, errUsage
+	}
+	fs.parsedLocales = locales
+	eng, err := fs.reload()
+	if err == nil && fs.cleanBackups != nil && *fs.cleanBackups {
+		err = eng.CleanBackups()
+	}
+	return eng, err
+}
+
+// reload creates engine and loads project again with already parsed flags
+func (fs *cmdFlags) reload() (*engine.Localizer, error) {
+	// options that are not given keep values of project config
+	newEngine := engine.New
+	if ext := strings.ToLower(filepath.Ext(fs.Arg(0))); ext == ".aar" || ext == ".zip" {
+		newEngine = engine.NewFromArchive
+	}
+	eng := newEngine(fs.Arg(0), fs.parsedLocales...).
+		SetStrict(*fs.strict).
+		SetImportLocales(fs.importLocales...).
+		SetJSONPlaceholderStyle(fs.style)
+	if *fs.stringsFile != "" {
+		eng.SetStringsFileName(*fs.stringsFile)
+	}
+	if *fs.module != "" {
+		eng.SetModule(*fs.module)
+	}
+	if fs.isSet("exclude") {
+		eng.SetExcludePatterns(splitList(*fs.exclude))
+	}
+	if fs.isSet("exclude-files") {
+		eng.SetExcludeFiles(splitList(*fs.excludeFiles)...)
+	}
+	if fs.isSet("normalize-whitespace") {
+		eng.SetNormalizeWhitespace(*fs.normalizeWS)
+	}
+	if fs.isSet("preserve-whitespace") {
+		eng.SetPreserveWhitespace(splitList(*fs.preserveWS)...)
+	}
+	if fs.isSet("canonical-locales") {
+		eng.SetCanonicalLocales(*fs.canonicalLocs)
+	}
+	if fs.isSet("backup") || fs.isSet("no-backup") {
+		eng.SetBackupMode(fs.backupMode)
+	}
+	if fs.delimiter != nil && *fs.delimiter != "" {
+		eng.SetDelimiter(fs.comma)
+	}
+	if fs.mapping != nil {
+		eng.SetColumnMapping(fs.mapping)
+	}
+	if fs.writeDefault != nil {
+		eng.SetWriteDefault(*fs.writeDefault).SetIndent(fs.indentation).SetUntranslatedPolicy(fs.policy).
+			SetKeepNonTranslatable(*fs.keepNonTrans).SetBackfill(*fs.backfill)
+		for loc, from := range fs.fallbacks {
+			eng.SetFallback(loc, from)
+		}
+		if *fs.pseudo != "" {
+			eng.SetPseudoLocale(*fs.pseudo)
+		}
+	}
+	if fs.review != nil {
+		eng.SetReviewIncomplete(*fs.review)
+	}
+	if fs.forceRefs != nil {
+		eng.SetOverwriteReferences(*fs.forceRefs)
+	}
+	if fs.normNewlines != nil {
+		eng.SetNormalizeNewlines(*fs.normNewlines)
+	}
+	if fs.utf8 != nil {
+		eng.SetPropertiesUTF8(*fs.utf8)
+	}
+	if fs.filter != nil {
+		eng.WithKeyPrefix(*fs.filter).WithKeyPattern(fs.keyPattern)
+	}
+	if *fs.verbose {
+		eng.SetLogger(log.New(os.Stderr, "", 0))
+	}
+	if eng.Load().Err() != nil {
+		return eng, eng.Err()
+	}
+	printWarnings(eng.Warnings())
+	return eng, nil
+}
+
+// isSet returns true if flag was given in command line
+func (fs *cmdFlags) isSet(name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// requireFlag prints usage if value of required flag is empty
+func (fs *cmdFlags) requireFlag(name, value string) error {
+	if value == "" {
+		fs.Output().Write([]byte(fmt.Sprintf("flag -%s is required\n", name)))
+		fs.Usage()
+		return errUsage
+	}
+	return nil
+}
+
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+// parseFallbacks returns locales missing values are taken from by locale given as locale:from pairs
+func parseFallbacks(s string) (map[string]string, error) {
+	res := map[string]string{}
+	for _, pair := range splitList(s) {
+		locs := strings.SplitN(pair, ":", 2)
+		if len(locs) != 2 {
+			return nil, fmt.Errorf("invalid -fallback value '%s': should be locale:from", pair)
+		}
+		for i, loc := range locs {
+			if i == 1 && loc == "def" {
+				continue
+			}
+			norm, err := engine.NormalizeLocale(loc)
+			if err != nil {
+				return nil, fmt.Errorf("invalid -fallback value: %v", err)
+			}
+			locs[i] = norm
+		}
+		res[locs[0]] = locs[1]
+	}
+	return res, nil
+}
+
+// parseDelimiter returns delimiter given as single character or "tab"
+func parseDelimiter(s string) (rune, error) {
+	if s == "tab" {
+		return '\t', nil
+	}
+	d, size := utf8.DecodeRuneInString(s)
+	if size != len(s) {
+		return 0, fmt.Errorf("invalid -delimiter value '%s': should be single character or tab", s)
+	}
+	return d, nil
+}
+
+// parseIndent returns indentation given as count of spaces or "tab"
+func parseIndent(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	if s == "tab" {
+		return "\t", nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n <= 0 {
+		return "", fmt.Errorf("invalid -indent value '%s': expected count of spaces or tab", s)
+	}
+	return strings.Repeat(" ", n), nil
+}
+
+func parseLocales(s string) ([]string, error) {
+	var locales []string
+	for _, loc := range splitList(s) {
+		if loc == "" {
+			return nil, fmt.Errorf("invalid -locales value '%s': empty locale name", s)
+		}
+		norm, err := engine.NormalizeLocale(loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -locales value: %v", err)
+		}
+		locales = append(locales, norm)
 	}
+	return locales, nil
 }
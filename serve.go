@@ -0,0 +1,300 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/vc2402/localizer/engine"
+)
+
+const stringsAPIPath = "/api/strings"
+
+// server serves web UI for editing values of loaded project; edits are kept in engine until saved
+type server struct {
+	mu    sync.Mutex
+	eng   *engine.Localizer
+	dirty bool
+	// host is host server listens on
+	host string
+}
+
+type apiString struct {
+	Name    string            `json:"name"`
+	Comment string            `json:"comment,omitempty"`
+	Values  map[string]string `json:"values"`
+	Status  map[string]string `json:"status,omitempty"`
+	// ETags contains tags of values by locale for If-Match header of updates
+	ETags map[string]string `json:"etags"`
+}
+
+type apiStrings struct {
+	Locales []string    `json:"locales"`
+	Strings []apiString `json:"strings"`
+	// Dirty is true if there are edits that are not saved
+	Dirty bool `json:"dirty"`
+}
+
+func serveCmd(args []string) error {
+	fs := newFlagSet("serve")
+	fs.saveFlags()
+	portF := fs.Int("port", 8080, "`port` to listen on")
+	hostF := fs.String("host", "localhost", "`host` to listen on")
+	eng, err := fs.load(args)
+	if err != nil {
+		// editing on top of broken resources would lose their content on save
+		return err
+	}
+	srv := &server{eng: eng, host: *hostF}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", srv.page)
+	mux.HandleFunc(stringsAPIPath, srv.list)
+	mux.HandleFunc(stringsAPIPath+"/", srv.update)
+	mux.HandleFunc("/api/save", srv.save)
+	addr := fmt.Sprintf("%s:%d", *hostF, *portF)
+	fmt.Printf("serving %s at http://%s/, press Ctrl+C to stop\n", eng.ResourcesDir, addr)
+	return http.ListenAndServe(addr, mux)
+}
+
+func (s *server) page(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(servePage))
+}
+
+// list handles GET /api/strings?q=search&missing=locale returning translatable strings sorted by name
+func (s *server) list(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	query := strings.ToLower(r.URL.Query().Get("q"))
+	missing := r.URL.Query().Get("missing")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := apiStrings{Locales: s.eng.Locales[1:], Strings: []apiString{}, Dirty: s.dirty}
+	for _, str := range s.eng.Strings() {
+		if !str.Translatable || str.IsReference() {
+			continue
+		}
+		if query != "" && !strings.Contains(strings.ToLower(str.Name), query) &&
+			!strings.Contains(strings.ToLower(str.Values["def"]), query) {
+			continue
+		}
+		if missing != "" && str.IsTranslated(missing) {
+			continue
+		}
+		as := apiString{Name: str.Name, Comment: str.Comment, Values: str.Values, Status: str.Status,
+			ETags: map[string]string{}}
+		for _, loc := range res.Locales {
+			as.ETags[loc] = etag(str.Values[loc])
+		}
+		res.Strings = append(res.Strings, as)
+	}
+	sort.Slice(res.Strings, func(i, j int) bool {
+		return res.Strings[i].Name < res.Strings[j].Name
+	})
+	writeJSON(w, http.StatusOK, res)
+}
+
+// update handles PUT /api/strings/{name}/{locale} with {"value": "..."} body: last write wins
+// unless If-Match header with tag of the value the edit is based on is given
+func (s *server) update(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.trusted(r) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(r.URL.EscapedPath(), stringsAPIPath+"/"), "/")
+	if len(parts) != 2 {
+		http.NotFound(w, r)
+		return
+	}
+	name, err1 := url.PathUnescape(parts[0])
+	loc, err2 := url.PathUnescape(parts[1])
+	body := struct {
+		Value string `json:"value"`
+	}{}
+	if err1 != nil || err2 != nil || json.NewDecoder(r.Body).Decode(&body) != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	str, ok := s.eng.Strings()[name]
+	if !ok || !contains(s.eng.Locales[1:], loc) {
+		http.NotFound(w, r)
+		return
+	}
+	if !str.Translatable || str.IsReference() {
+		http.Error(w, fmt.Sprintf("string '%s' is not translatable", name), http.StatusConflict)
+		return
+	}
+	if match := r.Header.Get("If-Match"); match != "" && match != etag(str.Values[loc]) {
+		writeJSON(w, http.StatusPreconditionFailed, map[string]string{"value": str.Values[loc], "etag": etag(str.Values[loc])})
+		return
+	}
+	value := ""
+	if body.Value == "" {
+		delete(str.Values, loc)
+	} else {
+		// value is stored the way imports store values, so it is valid android xml
+		value = s.eng.ImportedValue(str, loc, body.Value)
+		str.Values[loc] = value
+	}
+	// value is edited by a person now, so machine or memory status is not relevant anymore
+	str.SetStatus(loc, "")
+	s.dirty = true
+	w.Header().Set("ETag", etag(value))
+	writeJSON(w, http.StatusOK, map[string]string{"value": value, "etag": etag(value)})
+}
+
+// trusted returns false for requests that change data if they come from pages of other sites (Origin differs
+// from Host) or, for server listening on loopback, are sent to other host name (e.g. by dns rebinding)
+func (s *server) trusted(r *http.Request) bool {
+	if isLoopback(s.host) && !isLoopback(hostName(r.Host)) {
+		return false
+	}
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		// not a browser request
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
+
+// hostName returns host of host:port
+func hostName(hostPort string) string {
+	if host, _, err := net.SplitHostPort(hostPort); err == nil {
+		return host
+	}
+	return hostPort
+}
+
+// isLoopback returns true for localhost and loopback addresses
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// save handles POST /api/save saving edited values to project
+func (s *server) save(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.trusted(r) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.eng.Save(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.dirty = false
+	var warnings []string
+	for _, e := range s.eng.SaveWarnings() {
+		warnings = append(warnings, e.Error())
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"changed": s.eng.ChangedFiles(), "warnings": warnings})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// etag returns entity tag of value
+func etag(value string) string {
+	h := sha256.Sum256([]byte(value))
+	return `"` + hex.EncodeToString(h[:8]) + `"`
+}
+
+const servePage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>localizer</title>
+<style>
+body { font-family: sans-serif; margin: 1em; }
+table { border-collapse: collapse; width: 100%; }
+td, th { border: 1px solid #ccc; padding: 4px; vertical-align: top; text-align: left; }
+td.name { font-family: monospace; white-space: nowrap; }
+td.missing { background: #fee; }
+textarea { width: 100%; box-sizing: border-box; font: inherit; }
+.comment { color: #777; font-size: smaller; }
+#status { margin-left: 1em; color: #777; }
+</style>
+</head>
+<body>
+<div>
+<input id="q" placeholder="search keys and values">
+<label>missing in <select id="missing"><option value="">-</option></select></label>
+<button id="save">Save to project</button><span id="status"></span>
+</div>
+<table><thead id="head"></thead><tbody id="rows"></tbody></table>
+<script>
+let locales = [];
+const $ = id => document.getElementById(id);
+const esc = s => (s || "").replace(/[&<>"]/g, c => ({"&": "&amp;", "<": "&lt;", ">": "&gt;", '"': "&quot;"}[c]));
+function status(text) { $("status").textContent = text; }
+async function load() {
+  const params = new URLSearchParams({q: $("q").value, missing: $("missing").value});
+  const data = await (await fetch("/api/strings?" + params)).json();
+  if (locales.length === 0) {
+    locales = data.locales;
+    for (const loc of locales) $("missing").add(new Option(loc, loc));
+  }
+  $("head").innerHTML = "<tr><th>key</th><th>default</th>" + locales.map(l => "<th>" + esc(l) + "</th>").join("") + "</tr>";
+  $("rows").innerHTML = data.strings.map(s => "<tr><td class=name>" + esc(s.name) +
+    (s.comment ? "<div class=comment>" + esc(s.comment) + "</div>" : "") + "</td><td>" + esc(s.values.def) + "</td>" +
+    locales.map(l => "<td" + (s.values[l] ? "" : " class=missing") + "><textarea rows=2 data-name=\"" + esc(s.name) +
+      "\" data-loc=\"" + esc(l) + "\" data-etag='" + s.etags[l] + "'>" + esc(s.values[l]) + "</textarea></td>").join("") + "</tr>").join("");
+  status(data.dirty ? "unsaved changes" : "");
+}
+$("rows").addEventListener("change", async e => {
+  const t = e.target;
+  const resp = await fetch("/api/strings/" + encodeURIComponent(t.dataset.name) + "/" + encodeURIComponent(t.dataset.loc), {
+    method: "PUT", headers: {"If-Match": t.dataset.etag}, body: JSON.stringify({value: t.value})});
+  const data = await resp.json();
+  if (resp.status === 412) {
+    alert("Value of " + t.dataset.name + " was changed by someone else: " + data.value);
+  }
+  if (resp.ok || resp.status === 412) t.value = data.value;
+  t.dataset.etag = data.etag;
+  t.parentElement.className = t.value ? "" : "missing";
+  status(resp.ok ? "unsaved changes" : "");
+});
+$("save").addEventListener("click", async () => {
+  const resp = await fetch("/api/save", {method: "POST"});
+  if (!resp.ok) { status("save failed: " + await resp.text()); return; }
+  const data = await resp.json();
+  status("saved, " + (data.changed || []).length + " files changed" + (data.warnings ? "; " + data.warnings.join("; ") : ""));
+});
+$("q").addEventListener("input", load);
+$("missing").addEventListener("change", load);
+load();
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/vc2402/localizer/engine"
+)
+
+const watchDebounce = 300 * time.Millisecond
+
+// watchFile calls fn every time file is changed (rapid changes are coalesced) until interrupted
+func watchFile(fileName string, fn func()) error {
+	// editors often save files by renaming a temporary one, so the dir is watched, not the file
+	abs, err := filepath.Abs(fileName)
+	if err != nil {
+		return err
+	}
+	changed := func(name string) bool {
+		return name == abs
+	}
+	return watch([]string{filepath.Dir(abs)}, changed, func() {
+		if _, err := os.Stat(abs); err == nil {
+			fn()
+		}
+	})
+}
+
+// watch calls fn every time files of dirs for which changed returns true are changed, created or removed
+// (rapid changes are coalesced) until interrupted; dirs created in watched dirs are watched too
+func watch(dirs []string, changed func(name string) bool, fn func()) error {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	for _, dir := range dirs {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return err
+		}
+		if err = w.Add(abs); err != nil {
+			return err
+		}
+	}
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	var timer <-chan time.Time
+	for {
+		select {
+		case ev := <-w.Events:
+			name := filepath.Clean(ev.Name)
+			if ev.Op&fsnotify.Create != 0 {
+				if fi, err := os.Stat(name); err == nil && fi.IsDir() {
+					w.Add(name)
+					timer = time.After(watchDebounce)
+				}
+			}
+			if changed(name) && ev.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename|fsnotify.Remove) != 0 {
+				timer = time.After(watchDebounce)
+			}
+		case err = <-w.Errors:
+			return err
+		case <-timer:
+			timer = nil
+			fn()
+		case <-interrupt:
+			return nil
+		}
+	}
+}
+
+// watchExport exports resources to file every time they are changed; if resources can not be loaded
+// (e.g. file is being saved) the last export is kept
+func watchExport(fs *cmdFlags, eng *engine.Localizer, fileName, format string, setup func(eng *engine.Localizer)) error {
+	f, err := engine.FormatForFile(fileName, format)
+	if err != nil {
+		return err
+	}
+	if f.Export == nil {
+		return fmt.Errorf("format '%s' can not be exported to file, export it to dir", f.Name)
+	}
+	render := func(eng *engine.Localizer) ([]byte, error) {
+		buf := &bytes.Buffer{}
+		err := f.Export(eng, buf)
+		return buf.Bytes(), err
+	}
+	last, err := render(eng)
+	if err == nil {
+		err = eng.ExportFile(fileName, format)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s exported %s, watching %s, press Ctrl+C to stop\n", timestamp(), fileName, eng.ResourcesDir)
+	dirs := []string{eng.ResourcesDir}
+	files, err := ioutil.ReadDir(eng.ResourcesDir)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if f.IsDir() && strings.HasPrefix(f.Name(), "values") {
+			dirs = append(dirs, filepath.Join(eng.ResourcesDir, f.Name()))
+		}
+	}
+	changed := func(name string) bool {
+		return filepath.Ext(name) == ".xml"
+	}
+	return watch(dirs, changed, func() {
+		eng, err := fs.reload()
+		var content []byte
+		if err == nil {
+			setup(eng)
+			content, err = render(eng)
+		}
+		if err == nil && bytes.Equal(content, last) {
+			return
+		}
+		if err == nil {
+			err = eng.ExportFile(fileName, format)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s export failed, last export is kept: %v\n", timestamp(), err)
+			return
+		}
+		last = content
+		fmt.Printf("%s exported %s\n", timestamp(), fileName)
+	})
+}
+
+// watchImport imports file every time it is changed and saves resources
+func watchImport(fs *cmdFlags, fileName, format string, maxExpansion float64) error {
+	fmt.Printf("%s watching %s, press Ctrl+C to stop\n", timestamp(), fileName)
+	return watchFile(fileName, func() {
+		eng, err := fs.reload()
+		if err == nil {
+			before := snapshot(eng)
+			err = eng.ImportFile(fileName, format)
+			if err == nil {
+				printWarnings(eng.SetMaxExpansion(maxExpansion).ValidateLengths(nil))
+				err = save(eng)
+			}
+			if err == nil {
+				fmt.Printf("%s imported %s: %s\n", timestamp(), fileName, changesSummary(before, snapshot(eng)))
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s import failed: %v\n", timestamp(), err)
+		}
+	})
+}
+
+func timestamp() string {
+	return time.Now().Format("15:04:05")
+}
+
+// snapshot returns values of translatable strings by locale and name
+func snapshot(eng *engine.Localizer) map[string]map[string]string {
+	res := map[string]map[string]string{}
+	for n, s := range eng.Strings() {
+		if !s.Translatable {
+			continue
+		}
+		for loc, v := range s.Values {
+			if res[loc] == nil {
+				res[loc] = map[string]string{}
+			}
+			res[loc][n] = v
+		}
+	}
+	return res
+}
+
+func changesSummary(before, after map[string]map[string]string) string {
+	total := 0
+	byLocale := []string{}
+	for loc, values := range after {
+		changed := 0
+		for n, v := range values {
+			if old, ok := before[loc][n]; !ok || old != v {
+				changed++
+			}
+		}
+		if changed > 0 {
+			total += changed
+			byLocale = append(byLocale, fmt.Sprintf("%s: %d", loc, changed))
+		}
+	}
+	if total == 0 {
+		return "no changes"
+	}
+	sort.Strings(byLocale)
+	return fmt.Sprintf("%d values changed (%s)", total, strings.Join(byLocale, ", "))
+}